@@ -2,16 +2,26 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/asoasis/pii-redaction-api/internal/config"
 	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/gateway"
 	"github.com/asoasis/pii-redaction-api/internal/handler"
+	"github.com/asoasis/pii-redaction-api/internal/har"
+	"github.com/asoasis/pii-redaction-api/internal/logline"
 	"github.com/asoasis/pii-redaction-api/internal/middleware"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/otlp"
 	"github.com/asoasis/pii-redaction-api/internal/redactor"
 	"github.com/asoasis/pii-redaction-api/internal/store"
+	"github.com/asoasis/pii-redaction-api/internal/syslog"
+	"github.com/asoasis/pii-redaction-api/internal/tenantconfig"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
 	"github.com/go-chi/chi/v5"
@@ -38,7 +48,93 @@ func main() {
 	}
 
 	pipeline := detector.NewPipeline("en-US", cfg.EnableNER)
+	if cfg.NERRemoteURL != "" {
+		remote := detector.RemoteNERConfig{
+			URL:              cfg.NERRemoteURL,
+			APIKey:           cfg.NERRemoteAPIKey,
+			Timeout:          cfg.NERRemoteTimeout,
+			BatchSize:        cfg.NERRemoteBatchSize,
+			BatchWindow:      cfg.NERRemoteBatchWindow,
+			FailureThreshold: cfg.NERRemoteFailureThreshold,
+			Cooldown:         cfg.NERRemoteCooldown,
+		}
+		if cfg.NERRemoteFallback {
+			remote.Fallback = detector.NewNERDetector()
+		}
+		// The remote detector replaces local prose, which only runs as its fallback.
+		pipeline.Registry().Register(detector.NewRemoteNERDetector(remote), detector.DetectorOptions{})
+		pipeline.Registry().SetEnabled("ner", false)
+	}
+	if cfg.DetectorTimeout > 0 {
+		for _, name := range pipeline.Registry().Names() {
+			pipeline.Registry().SetTimeout(name, cfg.DetectorTimeout)
+		}
+	}
+	for _, name := range cfg.DisabledDetectors {
+		if err := pipeline.Registry().SetEnabled(name, false); err != nil {
+			log.Fatal().Err(err).Msg("Invalid DISABLED_DETECTORS")
+		}
+	}
+	ensemble := detector.Ensemble{Aggregation: model.EnsembleAggregation(cfg.EnsembleAggregation), Weights: cfg.EnsembleWeights}
+	if err := pipeline.SetEnsemble(ensemble); err != nil {
+		log.Fatal().Err(err).Msg("Invalid ENSEMBLE_AGGREGATION")
+	}
+	pipeline.SetCoreference(detector.Coreference{Enabled: cfg.CoreferenceEnabled, MinConfidence: cfg.CoreferenceMinConfidence})
+	var configStore store.ConfigStore
+	if cfg.ConfigStoreDir != "" {
+		configStore, err = store.NewConfigStore(cfg.ConfigStoreDir)
+	} else {
+		configStore, err = store.NewDynamoDBConfigStore(ctx, cfg.AWSRegion, cfg.ConfigTableName)
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize config store")
+	}
+	regexDetector, _ := pipeline.Registry().Get("regex")
+	regexDetector.(*detector.RegexDetector).SetNormalization(cfg.TextNormalization)
+	phoneDetector, _ := pipeline.Registry().Get("phone")
+	phoneDetector.(*detector.PhoneDetector).SetNormalization(cfg.TextNormalization)
+	patternSvc := tenantconfig.NewPatternService(configStore, regexDetector.(*detector.RegexDetector))
+	if err := patternSvc.Load(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Failed to load custom patterns")
+	}
+	if cfg.ContextRulesFile != "" {
+		data, err := os.ReadFile(cfg.ContextRulesFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to read CONTEXT_RULES_FILE")
+		}
+		rules, err := detector.ParseContextRules(data)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid CONTEXT_RULES_FILE")
+		}
+		if err := pipeline.ContextAnalyzer().SetRules(rules); err != nil {
+			log.Fatal().Err(err).Msg("Invalid CONTEXT_RULES_FILE")
+		}
+	}
+	ruleSvc := tenantconfig.NewRuleService(configStore, pipeline.ContextAnalyzer())
+	if err := ruleSvc.Load(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Failed to load context rules")
+	}
+	policySvc := tenantconfig.NewPolicyService(configStore, pipeline)
+	if err := policySvc.Load(ctx); err != nil {
+		log.Fatal().Err(err).Msg("Failed to load tenant policies")
+	}
+
+	refresher := tenantconfig.NewRefresher(cfg.ConfigRefresh, patternSvc, ruleSvc, policySvc)
+
+	apiKeys, err := middleware.NewAPIKeys(cfg.APIKey, cfg.TenantAPIKeys)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid TENANT_API_KEYS")
+	}
+
 	redactorSvc := redactor.NewRedactor(dynamoStore)
+	harSvc := har.NewRedactor(pipeline, redactorSvc)
+	logProcessor := logline.NewProcessor(pipeline, redactorSvc)
+	logDefaults := logline.Options{
+		Format:        logline.FormatAuto,
+		RedactFields:  cfg.LogRedactFields,
+		MessageFields: cfg.LogMessageFields,
+		Malformed:     logline.MalformedPolicy(cfg.LogMalformed),
+	}
 
 	r := chi.NewRouter()
 	r.Use(chimiddleware.RequestID)
@@ -64,11 +160,50 @@ func main() {
 
 	r.Get("/v1/health", handler.Health)
 
-	r.Post("/v1/detect", handler.NewDetectHandler(pipeline).ServeHTTP)
+	r.With(middleware.Tenant(apiKeys), middleware.RefreshConfig(refresher)).Post("/v1/detect", handler.NewDetectHandler(pipeline).ServeHTTP)
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.Auth(cfg.APIKey))
+		r.Use(middleware.Auth(apiKeys))
+		r.Use(middleware.RefreshConfig(refresher))
 		r.Post("/v1/redact", handler.NewRedactHandler(pipeline, redactorSvc).ServeHTTP)
+		r.Post("/v1/redact/har", handler.NewHARHandler(harSvc).ServeHTTP)
+		r.Post("/v1/redact/http", handler.NewHTTPTranscriptHandler(harSvc).ServeHTTP)
+		r.Post("/v1/redact/logs", handler.NewLogsHandler(logProcessor, logDefaults).ServeHTTP)
 		r.Post("/v1/detokenize", handler.NewDetokenizeHandler(redactorSvc).ServeHTTP)
+
+		patterns := handler.NewPatternsHandler(patternSvc)
+		r.Get("/v1/patterns", patterns.List)
+		r.Post("/v1/patterns", patterns.Create)
+		r.Post("/v1/patterns/test", patterns.Test)
+		r.Put("/v1/patterns/{name}", patterns.Update)
+		r.Delete("/v1/patterns/{name}", patterns.Delete)
+
+		contextRules := handler.NewContextRulesHandler(ruleSvc)
+		r.Get("/v1/context-rules", contextRules.Get)
+		r.Put("/v1/context-rules", contextRules.Put)
+		r.Delete("/v1/context-rules", contextRules.Delete)
+
+		policy := handler.NewPolicyHandler(policySvc)
+		r.Get("/v1/policy", policy.Get)
+		r.Put("/v1/policy", policy.Put)
+		r.Delete("/v1/policy", policy.Delete)
+
+		if cfg.OTLPExportEndpoint != "" {
+			otlpProcessor := otlp.NewProcessor(pipeline, redactorSvc, otlp.Options{
+				Attributes: cfg.OTLPRedactAttrs,
+				Request:    model.RedactionRequest{Mode: model.RedactionMode(cfg.OTLPRedactMode)},
+			})
+			otlpExporter := otlp.NewExporter(cfg.OTLPExportEndpoint, cfg.OTLPExportHeaders, cfg.OTLPExportTimeout)
+			r.Post("/v1/logs", handler.NewOTLPLogsHandler(otlpProcessor, otlpExporter).ServeHTTP)
+		}
+
+		if cfg.LLMUpstreamURL != "" {
+			llmGateway := gateway.New(gateway.Config{
+				UpstreamURL:    cfg.LLMUpstreamURL,
+				UpstreamAPIKey: cfg.LLMUpstreamAPIKey,
+				SessionTTL:     cfg.LLMSessionTTL,
+			}, pipeline, redactorSvc)
+			r.Post("/v1/chat/completions", llmGateway.ServeHTTP)
+		}
 	})
 
 	// Check if running in Lambda
@@ -77,10 +212,74 @@ func main() {
 		adapter := httpadapter.NewV2(r)
 		lambda.Start(adapter.ProxyWithContext)
 	} else {
+		var syslogServer *syslog.Server
+		if cfg.SyslogEnabled {
+			if syslogServer, err = startSyslog(ctx, cfg, pipeline, redactorSvc); err != nil {
+				log.Fatal().Err(err).Msg("Failed to start syslog receiver")
+			}
+		}
+
+		// The syslog receiver keeps using ctx while it drains, so shutdown is signalled
+		// separately.
+		stopped, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
 
+		srv := &http.Server{Addr: ":" + cfg.Port, Handler: r}
+		served := make(chan error, 1)
+		go func() { served <- srv.ListenAndServe() }()
 		log.Info().Str("port", cfg.Port).Msg("Starting PII Redaction API locally")
-		if err := http.ListenAndServe(":"+cfg.Port, r); err != nil {
+
+		select {
+		case err = <-served:
+		case <-stopped.Done():
+			log.Info().Msg("Shutting down")
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			err = srv.Shutdown(shutdownCtx)
+			cancel()
+		}
+
+		// log.Fatal exits without running deferred calls, so the syslog receiver is
+		// drained and its forwarder flushed before any exit.
+		if syslogServer != nil {
+			if err := syslogServer.Close(); err != nil {
+				log.Error().Err(err).Msg("Failed to close syslog receiver")
+			}
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal().Err(err).Msg("Server failed")
 		}
 	}
 }
+
+func startSyslog(ctx context.Context, cfg config.Config, pipeline *detector.Pipeline, redactorSvc *redactor.Redactor) (*syslog.Server, error) {
+	if cfg.SyslogForward == "" {
+		return nil, errors.New("SYSLOG_FORWARD must be set when SYSLOG_ENABLED is true")
+	}
+	forwarder, err := syslog.NewForwarder(cfg.SyslogForward)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies syslog.Policies
+	if cfg.SyslogPolicyFile != "" {
+		if policies, err = syslog.LoadPolicies(cfg.SyslogPolicyFile); err != nil {
+			return nil, err
+		}
+	}
+
+	server, err := syslog.NewServer(syslog.Config{
+		UDPAddr:   cfg.SyslogUDPAddr,
+		TCPAddr:   cfg.SyslogTCPAddr,
+		QueueSize: cfg.SyslogQueueSize,
+		Workers:   cfg.SyslogWorkers,
+		Policies:  policies,
+	}, pipeline, redactorSvc, forwarder)
+	if err != nil {
+		return nil, err
+	}
+	if err := server.Start(ctx); err != nil {
+		return nil, err
+	}
+	log.Info().Str("udp", cfg.SyslogUDPAddr).Str("tcp", cfg.SyslogTCPAddr).Str("forward", cfg.SyslogForward).Msg("Syslog receiver started")
+	return server, nil
+}
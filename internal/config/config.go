@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 )
 
@@ -11,6 +13,54 @@ type Config struct {
 	DynamoTableName string `envconfig:"DYNAMO_TABLE_NAME" default:"pii-tokens"`
 	APIKey          string `envconfig:"API_KEY" default:"sk_test_123"`
 	EnableNER       bool   `envconfig:"ENABLE_NER" default:"false"`
+
+	TenantAPIKeys map[string]string `envconfig:"TENANT_API_KEYS"`
+
+	ConfigTableName   string        `envconfig:"CONFIG_TABLE_NAME" default:"pii-config"`
+	ConfigStoreDir    string        `envconfig:"CONFIG_STORE_DIR"`
+	ConfigRefresh     time.Duration `envconfig:"CONFIG_REFRESH_INTERVAL" default:"30s"`
+	DisabledDetectors []string      `envconfig:"DISABLED_DETECTORS"`
+	DetectorTimeout   time.Duration `envconfig:"DETECTOR_TIMEOUT" default:"0"`
+	ContextRulesFile  string        `envconfig:"CONTEXT_RULES_FILE"`
+
+	NERRemoteURL              string        `envconfig:"NER_REMOTE_URL"`
+	NERRemoteAPIKey           string        `envconfig:"NER_REMOTE_API_KEY"`
+	NERRemoteTimeout          time.Duration `envconfig:"NER_REMOTE_TIMEOUT" default:"2s"`
+	NERRemoteBatchSize        int           `envconfig:"NER_REMOTE_BATCH_SIZE" default:"16"`
+	NERRemoteBatchWindow      time.Duration `envconfig:"NER_REMOTE_BATCH_WINDOW" default:"5ms"`
+	NERRemoteFailureThreshold int           `envconfig:"NER_REMOTE_FAILURE_THRESHOLD" default:"5"`
+	NERRemoteCooldown         time.Duration `envconfig:"NER_REMOTE_COOLDOWN" default:"30s"`
+	NERRemoteFallback         bool          `envconfig:"NER_REMOTE_FALLBACK" default:"true"`
+
+	TextNormalization bool `envconfig:"TEXT_NORMALIZATION" default:"true"`
+
+	CoreferenceEnabled       bool    `envconfig:"COREFERENCE_ENABLED" default:"true"`
+	CoreferenceMinConfidence float64 `envconfig:"COREFERENCE_MIN_CONFIDENCE" default:"0.80"`
+
+	EnsembleAggregation string             `envconfig:"ENSEMBLE_AGGREGATION" default:"noisy_or"`
+	EnsembleWeights     map[string]float64 `envconfig:"ENSEMBLE_WEIGHTS"`
+
+	LogRedactFields  []string `envconfig:"LOG_REDACT_FIELDS" default:"password,passwd,secret,token,api_key,authorization"`
+	LogMessageFields []string `envconfig:"LOG_MESSAGE_FIELDS" default:"msg,message"`
+	LogMalformed     string   `envconfig:"LOG_MALFORMED" default:"pass"`
+
+	SyslogEnabled    bool   `envconfig:"SYSLOG_ENABLED" default:"false"`
+	SyslogUDPAddr    string `envconfig:"SYSLOG_UDP_ADDR" default:":5514"`
+	SyslogTCPAddr    string `envconfig:"SYSLOG_TCP_ADDR" default:":5514"`
+	SyslogForward    string `envconfig:"SYSLOG_FORWARD"`
+	SyslogQueueSize  int    `envconfig:"SYSLOG_QUEUE_SIZE" default:"1024"`
+	SyslogWorkers    int    `envconfig:"SYSLOG_WORKERS" default:"4"`
+	SyslogPolicyFile string `envconfig:"SYSLOG_POLICY_FILE"`
+
+	OTLPExportEndpoint string            `envconfig:"OTLP_EXPORT_ENDPOINT"`
+	OTLPExportHeaders  map[string]string `envconfig:"OTLP_EXPORT_HEADERS"`
+	OTLPExportTimeout  time.Duration     `envconfig:"OTLP_EXPORT_TIMEOUT" default:"10s"`
+	OTLPRedactAttrs    []string          `envconfig:"OTLP_REDACT_ATTRIBUTES" default:"user.email,user.name,enduser.id,http.url,url.full,client.address"`
+	OTLPRedactMode     string            `envconfig:"OTLP_REDACT_MODE" default:"replace"`
+
+	LLMUpstreamURL    string        `envconfig:"LLM_UPSTREAM_URL"`
+	LLMUpstreamAPIKey string        `envconfig:"LLM_UPSTREAM_API_KEY"`
+	LLMSessionTTL     time.Duration `envconfig:"LLM_SESSION_TTL" default:"1h"`
 }
 
 func Load() (Config, error) {
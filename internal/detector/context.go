@@ -2,47 +2,443 @@ package detector
 
 import (
 	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+	"gopkg.in/yaml.v3"
 )
 
-type ContextAnalyzer struct{}
+//go:embed context_rules.yaml
+var defaultContextRules []byte
+
+const defaultRuleWindow = 5
+
+// ErrInvalidContextRule is returned for rule sets that fail validation.
+var ErrInvalidContextRule = errors.New("invalid context rule")
+
+// ContextAnalyzer refines detections with declarative context rules. Every tenant gets
+// the base rule set, with its own overrides merged in by rule ID.
+type ContextAnalyzer struct {
+	mu        sync.RWMutex
+	base      []model.ContextRule
+	overrides map[string][]model.ContextRule
+	effective map[string][]compiledRule
+	defaults  []compiledRule
+}
 
 func NewContextAnalyzer() *ContextAnalyzer {
-	return &ContextAnalyzer{}
+	a := &ContextAnalyzer{
+		overrides: make(map[string][]model.ContextRule),
+		effective: make(map[string][]compiledRule),
+	}
+	if err := a.SetRules(DefaultContextRules()); err != nil {
+		panic(fmt.Sprintf("built-in context rules are invalid: %v", err))
+	}
+	return a
+}
+
+// DefaultContextRules returns the built-in rule set.
+func DefaultContextRules() []model.ContextRule {
+	rules, err := ParseContextRules(defaultContextRules)
+	if err != nil {
+		panic(fmt.Sprintf("built-in context rules are invalid: %v", err))
+	}
+	return rules
+}
+
+// ParseContextRules decodes a YAML or JSON list of rules and validates it.
+func ParseContextRules(data []byte) ([]model.ContextRule, error) {
+	var rules []model.ContextRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContextRule, err)
+	}
+	return rules, ValidateContextRules(rules)
+}
+
+// ValidateContextRules checks that every rule is well-formed and IDs are unique.
+func ValidateContextRules(rules []model.ContextRule) error {
+	seen := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if r.ID == "" {
+			return fmt.Errorf("%w: rule without id", ErrInvalidContextRule)
+		}
+		if seen[r.ID] {
+			return fmt.Errorf("%w: duplicate rule id %q", ErrInvalidContextRule, r.ID)
+		}
+		seen[r.ID] = true
+		if r.Disabled {
+			continue
+		}
+		if r.EntityType == "" {
+			return fmt.Errorf("%w: rule %q has no entity_type", ErrInvalidContextRule, r.ID)
+		}
+		switch r.Action {
+		case model.BoostAction, model.PenaltyAction:
+			if r.Amount <= 0 || r.Amount > 1 {
+				return fmt.Errorf("%w: rule %q needs an amount in (0, 1]", ErrInvalidContextRule, r.ID)
+			}
+		case model.ReclassifyAction:
+			if r.ReclassifyAs == "" {
+				return fmt.Errorf("%w: rule %q needs reclassify_as", ErrInvalidContextRule, r.ID)
+			}
+		case model.SuppressAction:
+		default:
+			return fmt.Errorf("%w: rule %q has unknown action %q", ErrInvalidContextRule, r.ID, r.Action)
+		}
+		switch r.Unit {
+		case "", "words", "sentences":
+		default:
+			return fmt.Errorf("%w: rule %q has unknown unit %q", ErrInvalidContextRule, r.ID, r.Unit)
+		}
+		switch r.Direction {
+		case "", "before", "after", "both":
+		default:
+			return fmt.Errorf("%w: rule %q has unknown direction %q", ErrInvalidContextRule, r.ID, r.Direction)
+		}
+		if r.Window < 0 {
+			return fmt.Errorf("%w: rule %q has a negative window", ErrInvalidContextRule, r.ID)
+		}
+	}
+	return nil
+}
+
+// SetRules replaces the base rule set shared by all tenants.
+func (a *ContextAnalyzer) SetRules(rules []model.ContextRule) error {
+	if err := ValidateContextRules(rules); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.base = rules
+	a.defaults = compileRules(rules)
+	for t, overrides := range a.overrides {
+		a.effective[t] = compileRules(mergeRules(a.base, overrides))
+	}
+	return nil
+}
+
+// SetTenantRules replaces a tenant's overrides. A rule whose ID matches a base rule
+// replaces it; other rules are appended. An empty slice removes the overrides.
+func (a *ContextAnalyzer) SetTenantRules(tenantID string, overrides []model.ContextRule) error {
+	if err := ValidateContextRules(overrides); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(overrides) == 0 {
+		delete(a.overrides, tenantID)
+		delete(a.effective, tenantID)
+		return nil
+	}
+	a.overrides[tenantID] = overrides
+	a.effective[tenantID] = compileRules(mergeRules(a.base, overrides))
+	return nil
+}
+
+// Rules returns the effective rules for a tenant.
+func (a *ContextAnalyzer) Rules(tenantID string) []model.ContextRule {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	rules := a.defaults
+	if eff, ok := a.effective[tenantID]; ok {
+		rules = eff
+	}
+	out := make([]model.ContextRule, len(rules))
+	for i, r := range rules {
+		out[i] = r.ContextRule
+	}
+	return out
+}
+
+func (a *ContextAnalyzer) rulesFor(tenantID string) []compiledRule {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if eff, ok := a.effective[tenantID]; ok {
+		return eff
+	}
+	return a.defaults
 }
 
 func (a *ContextAnalyzer) Refine(ctx context.Context, text string, detections []model.Detection) []model.Detection {
-	var refined []model.Detection
+	refined, _ := a.refine(ctx, text, detections)
+	return refined
+}
+
+// refine is Refine that also returns the detections suppressed by a rule. Detections
+// carrying an Explanation get every rule that fired recorded in it.
+func (a *ContextAnalyzer) refine(ctx context.Context, text string, detections []model.Detection) (refined, suppressed []model.Detection) {
+	rules := a.rulesFor(tenant.FromContext(ctx))
+	doc := newContextDoc(text)
+
 	for _, det := range detections {
-		// Example refinement: Boost confidence for SSN if keywords are nearby
-		if det.EntityType == "SSN" {
-			surrounding := getSurroundingText(text, det.Start, 30)
-			if containsAny(surrounding, "ssn", "social security", "tax id") {
-				det.Confidence = min(det.Confidence*1.1, 1.0)
+		isSuppressed := false
+		for _, rule := range rules {
+			if !EntityIsA(det.EntityType, rule.EntityType) {
+				continue
+			}
+			keyword, ok := rule.fires(doc, det)
+			if !ok {
+				continue
+			}
+			before := det.Confidence
+			switch rule.Action {
+			case model.BoostAction:
+				det.Confidence = min(det.Confidence+rule.Amount, 1.0)
+			case model.PenaltyAction:
+				det.Confidence = max(det.Confidence-rule.Amount, 0)
+			case model.ReclassifyAction:
+				det.EntityType = rule.ReclassifyAs
+			case model.SuppressAction:
+				isSuppressed = true
+			}
+			if e := det.Explanation; e != nil {
+				adj := model.Adjustment{
+					Stage: "context_rule", RuleID: rule.ID, Action: string(rule.Action), Keyword: keyword,
+					ConfidenceBefore: before, ConfidenceAfter: det.Confidence,
+				}
+				if rule.Action == model.ReclassifyAction {
+					adj.EntityType = rule.ReclassifyAs
+				}
+				e.Adjustments = append(e.Adjustments, adj)
+				if isSuppressed {
+					e.Decisions = append(e.Decisions, model.Decision{
+						Stage: "context_rule", Outcome: "dropped", Reason: "suppressed by rule " + rule.ID,
+					})
+				}
+			}
+			if isSuppressed {
+				break
 			}
 		}
 
-		// Disambiguate: "John Deere" (org) vs "Dear John" (person)
-		if det.EntityType == "PERSON" {
-			if strings.Contains(strings.ToLower(det.Text), "deere") || strings.Contains(strings.ToLower(det.Text), "inc") {
-				det.EntityType = "ORGANIZATION"
+		if isSuppressed {
+			suppressed = append(suppressed, det)
+		} else {
+			refined = append(refined, det)
+		}
+	}
+	return refined, suppressed
+}
+
+// mergeRules applies overrides to base by rule ID and drops disabled rules.
+func mergeRules(base, overrides []model.ContextRule) []model.ContextRule {
+	byID := make(map[string]model.ContextRule, len(overrides))
+	for _, r := range overrides {
+		byID[r.ID] = r
+	}
+	var merged []model.ContextRule
+	for _, r := range base {
+		if o, ok := byID[r.ID]; ok {
+			r = o
+			delete(byID, r.ID)
+		}
+		merged = append(merged, r)
+	}
+	for _, r := range overrides {
+		if _, ok := byID[r.ID]; ok {
+			merged = append(merged, r)
+		}
+	}
+	return merged
+}
+
+type compiledRule struct {
+	model.ContextRule
+	keywords [][]string
+	negative [][]string
+	inText   [][]string
+}
+
+func compileRules(rules []model.ContextRule) []compiledRule {
+	var compiled []compiledRule
+	for _, r := range rules {
+		if r.Disabled {
+			continue
+		}
+		if r.Window == 0 {
+			r.Window = defaultRuleWindow
+		}
+		compiled = append(compiled, compiledRule{
+			ContextRule: r,
+			keywords:    tokenizeKeywords(r.Keywords),
+			negative:    tokenizeKeywords(r.NegativeKeywords),
+			inText:      tokenizeKeywords(r.TextKeywords),
+		})
+	}
+	return compiled
+}
+
+func tokenizeKeywords(keywords []string) [][]string {
+	out := make([][]string, 0, len(keywords))
+	for _, k := range keywords {
+		var words []string
+		for _, w := range splitWords(k) {
+			words = append(words, w.text)
+		}
+		if len(words) > 0 {
+			out = append(out, words)
+		}
+	}
+	return out
+}
+
+// fires reports whether the rule applies to det, and the keyword that triggered it.
+func (r compiledRule) fires(doc *contextDoc, det model.Detection) (string, bool) {
+	keyword := ""
+	if len(r.inText) > 0 {
+		if keyword = findKeyword(doc.wordsIn(det.Start, det.End), r.inText); keyword == "" {
+			return "", false
+		}
+	}
+	if len(r.keywords) == 0 && len(r.negative) == 0 {
+		return keyword, true
+	}
+	window := doc.window(det, r.ContextRule)
+	if len(r.negative) > 0 && findKeyword(window, r.negative) != "" {
+		return "", false
+	}
+	if len(r.keywords) == 0 {
+		return keyword, true
+	}
+	if k := findKeyword(window, r.keywords); k != "" {
+		return k, true
+	}
+	return "", false
+}
+
+// findKeyword returns the first keyword found as a run of consecutive words, or "".
+func findKeyword(words []string, keywords [][]string) string {
+	for _, kw := range keywords {
+		for i := 0; i+len(kw) <= len(words); i++ {
+			match := true
+			for j, w := range kw {
+				if words[i+j] != w {
+					match = false
+					break
+				}
+			}
+			if match {
+				return strings.Join(kw, " ")
 			}
 		}
+	}
+	return ""
+}
 
-		// Filter out very low confidence
-		if det.Confidence >= 0.60 {
-			refined = append(refined, det)
+type word struct {
+	start, end int
+	text       string // lower-cased
+}
+
+// contextDoc is text split into words and sentences for rule windows.
+type contextDoc struct {
+	words     []word
+	sentences [][2]int
+}
+
+func newContextDoc(text string) *contextDoc {
+	return &contextDoc{words: splitWords(text), sentences: splitSentences(text)}
+}
+
+// window returns the lower-cased words in the rule's window around det.
+func (d *contextDoc) window(det model.Detection, r model.ContextRule) []string {
+	var before, after []string
+	if r.Direction != "after" {
+		from := 0
+		if r.Unit == "sentences" {
+			i := d.sentenceAt(det.Start) - (r.Window - 1)
+			from = d.sentences[maxInt(i, 0)][0]
+		}
+		before = d.wordsIn(from, det.Start)
+		if r.Unit != "sentences" && len(before) > r.Window {
+			before = before[len(before)-r.Window:]
 		}
 	}
-	return refined
+	if r.Direction != "before" {
+		to := int(^uint(0) >> 1)
+		if r.Unit == "sentences" {
+			i := d.sentenceAt(maxInt(det.End-1, det.Start)) + (r.Window - 1)
+			to = d.sentences[minInt(i, len(d.sentences)-1)][1]
+		}
+		after = d.wordsIn(det.End, to)
+		if r.Unit != "sentences" && len(after) > r.Window {
+			after = after[:r.Window]
+		}
+	}
+	return append(before, after...)
 }
 
-func getSurroundingText(text string, offset int, window int) string {
-	start := maxInt(0, offset-window)
-	end := minInt(len(text), offset+window)
-	return strings.ToLower(text[start:end])
+// wordsIn returns the words lying entirely within [start, end).
+func (d *contextDoc) wordsIn(start, end int) []string {
+	i := sort.Search(len(d.words), func(i int) bool { return d.words[i].start >= start })
+	var out []string
+	for ; i < len(d.words) && d.words[i].end <= end; i++ {
+		out = append(out, d.words[i].text)
+	}
+	return out
+}
+
+func (d *contextDoc) sentenceAt(offset int) int {
+	i := sort.Search(len(d.sentences), func(i int) bool { return d.sentences[i][1] > offset })
+	return minInt(i, len(d.sentences)-1)
+}
+
+// splitWords splits text into runs of letters and digits.
+func splitWords(text string) []word {
+	var words []word
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		} else if !isWord && start >= 0 {
+			words = append(words, word{start: start, end: i, text: strings.ToLower(text[start:i])})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		words = append(words, word{start: start, end: len(text), text: strings.ToLower(text[start:])})
+	}
+	return words
+}
+
+// splitSentences returns [start, end) byte ranges of sentences. A sentence ends at
+// '.', '!' or '?' followed by whitespace, or at a line break.
+func splitSentences(text string) [][2]int {
+	var sentences [][2]int
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		next := i + size
+		end := false
+		switch r {
+		case '\n':
+			end = true
+		case '.', '!', '?':
+			if next == len(text) {
+				end = true
+			} else if n, _ := utf8.DecodeRuneInString(text[next:]); unicode.IsSpace(n) {
+				end = true
+			}
+		}
+		if end {
+			sentences = append(sentences, [2]int{start, next})
+			start = next
+		}
+		i = next
+	}
+	if start < len(text) || len(sentences) == 0 {
+		sentences = append(sentences, [2]int{start, len(text)})
+	}
+	return sentences
 }
 
 func minInt(a, b int) int {
@@ -59,13 +455,14 @@ func maxInt(a, b int) int {
 	return b
 }
 
-func containsAny(text string, keywords ...string) bool {
+// firstKeyword returns the first of keywords contained in text, or "".
+func firstKeyword(text string, keywords []string) string {
 	for _, k := range keywords {
 		if strings.Contains(text, k) {
-			return true
+			return k
 		}
 	}
-	return false
+	return ""
 }
 
 func min(a, b float64) float64 {
@@ -74,3 +471,10 @@ func min(a, b float64) float64 {
 	}
 	return b
 }
+
+func max(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
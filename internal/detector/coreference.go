@@ -0,0 +1,351 @@
+package detector
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+// Coreference configures the stage that propagates detected names to their other
+// mentions in the same document.
+type Coreference struct {
+	Enabled bool
+	// MinConfidence is the confidence a PERSON detection needs to seed propagation.
+	MinConfidence float64
+}
+
+// DefaultCoreference is the coreference configuration of a new Pipeline.
+var DefaultCoreference = Coreference{Enabled: true, MinConfidence: 0.80}
+
+// Confidence of each kind of variant, as a fraction of the seed's confidence.
+var variantWeights = map[string]float64{
+	"full_name":  1.00,
+	"honorific":  0.95,
+	"surname":    0.90,
+	"first_name": 0.85,
+	"nickname":   0.80,
+}
+
+var honorifics = []string{"Mr", "Mrs", "Ms", "Miss", "Mx", "Dr", "Prof", "Sir", "Dame"}
+
+// nicknameGroups lists given names that refer to the same person.
+var nicknameGroups = [][]string{
+	{"Jonathan", "Jon", "Jonny"},
+	{"John", "Johnny", "Jack"},
+	{"William", "Will", "Bill", "Billy", "Liam"},
+	{"Robert", "Rob", "Bob", "Bobby"},
+	{"Richard", "Rick", "Dick", "Rich"},
+	{"James", "Jim", "Jimmy", "Jamie"},
+	{"Michael", "Mike", "Mikey"},
+	{"Christopher", "Chris"},
+	{"Christine", "Chris", "Christy"},
+	{"Nicholas", "Nick", "Nicky"},
+	{"Anthony", "Tony"},
+	{"Joseph", "Joe", "Joey"},
+	{"Thomas", "Tom", "Tommy"},
+	{"Daniel", "Dan", "Danny"},
+	{"Matthew", "Matt"},
+	{"Andrew", "Andy", "Drew"},
+	{"Benjamin", "Ben", "Benny"},
+	{"Samuel", "Sam", "Sammy"},
+	{"Alexander", "Alex", "Sasha"},
+	{"Edward", "Ed", "Eddie", "Ted"},
+	{"Steven", "Steve"},
+	{"Stephen", "Steve"},
+	{"Timothy", "Tim"},
+	{"Patrick", "Pat"},
+	{"Charles", "Charlie", "Chuck"},
+	{"Elizabeth", "Liz", "Beth", "Betty", "Eliza"},
+	{"Katherine", "Kate", "Katie", "Kathy"},
+	{"Catherine", "Cathy", "Kate"},
+	{"Margaret", "Maggie", "Meg", "Peggy"},
+	{"Jennifer", "Jen", "Jenny"},
+	{"Jessica", "Jess", "Jessie"},
+	{"Rebecca", "Becky", "Becca"},
+	{"Susan", "Sue", "Susie"},
+	{"Deborah", "Deb", "Debbie"},
+	{"Patricia", "Pat", "Patty", "Trish"},
+	{"Victoria", "Vicky", "Tori"},
+	{"Alexandra", "Alex", "Sandra"},
+	{"Samantha", "Sam"},
+	{"Abigail", "Abby"},
+	{"Kimberly", "Kim"},
+}
+
+var nicknames = func() map[string][]string {
+	m := make(map[string][]string)
+	for _, group := range nicknameGroups {
+		for _, name := range group {
+			for _, other := range group {
+				if other != name {
+					m[name] = append(m[name], other)
+				}
+			}
+		}
+	}
+	return m
+}()
+
+type nameVariant struct {
+	kind    string
+	pattern *regexp.Regexp
+}
+
+type corefEntity struct {
+	id          string
+	first, last string
+	confidence  float64
+	start       int // First seed mention, to resolve variants shared by several entities
+	variants    []nameVariant
+}
+
+// propagateNames links PERSON detections of the same name with an EntityID and adds
+// detections for the other mentions of each name: surname, first name, nicknames and
+// honorific plus surname. A first name or nickname followed by another surname, as in
+// "Jonathan Millerson", names someone else and is left alone. Possessives are covered
+// because a mention only needs a word boundary after it.
+func propagateNames(text string, detections []model.Detection, cfg Coreference, explain bool) []model.Detection {
+	if !cfg.Enabled {
+		return detections
+	}
+
+	// Seeds with the same full name share an entity, and a single-word seed joins the
+	// entity it is a first name, surname or nickname of. Full names are seen first.
+	var seeds []model.Detection
+	for _, det := range detections {
+		if EntityIsA(det.EntityType, "PERSON") && det.Confidence >= cfg.MinConfidence && det.DetectionMethod != "coreference" {
+			seeds = append(seeds, det)
+		}
+	}
+	sort.SliceStable(seeds, func(i, j int) bool {
+		return len(strings.Fields(seeds[i].Text)) > len(strings.Fields(seeds[j].Text))
+	})
+
+	var entities []*corefEntity
+	for _, det := range seeds {
+		first, last := splitName(det.Text)
+		if first == "" {
+			continue
+		}
+		e := findEntity(entities, first, last)
+		if e == nil {
+			e = &corefEntity{id: "e" + strconv.Itoa(len(entities)+1), first: first, last: last, start: det.Start}
+			e.variants = nameVariants(first, last)
+			entities = append(entities, e)
+		}
+		e.confidence = max(e.confidence, det.Confidence)
+		e.start = minInt(e.start, det.Start)
+	}
+	if len(entities) == 0 {
+		return detections
+	}
+
+	type mention struct {
+		start, end int
+		kind       string
+		entity     *corefEntity
+	}
+	var mentions []mention
+	for _, e := range entities {
+		for _, v := range e.variants {
+			for _, m := range v.pattern.FindAllStringSubmatchIndex(text, -1) {
+				start, end := m[2], m[3]
+				if !wordBoundary(text, start, end) {
+					continue
+				}
+				if (v.kind == "first_name" || v.kind == "nickname") && otherSurname(text, end, e.last) {
+					continue
+				}
+				mentions = append(mentions, mention{start: start, end: end, kind: v.kind, entity: e})
+			}
+		}
+	}
+	// Longer mentions claim their span first; a variant shared by several entities goes
+	// to the entity whose first mention is closest before it.
+	sort.SliceStable(mentions, func(i, j int) bool {
+		a, b := mentions[i], mentions[j]
+		if a.start != b.start {
+			return a.start < b.start
+		}
+		if a.end != b.end {
+			return a.end > b.end
+		}
+		return closer(a.entity, b.entity, a.start)
+	})
+
+	claimed := func(start, end int, taken []mention) bool {
+		for _, t := range taken {
+			if start < t.end && t.start < end {
+				return true
+			}
+		}
+		return false
+	}
+	var taken []mention
+	for _, m := range mentions {
+		if !claimed(m.start, m.end, taken) {
+			taken = append(taken, m)
+		}
+	}
+
+	for _, m := range taken {
+		linked := false
+		for i := range detections {
+			d := &detections[i]
+			if EntityIsA(d.EntityType, "PERSON") && d.Start >= m.start && d.End <= m.end {
+				d.EntityID = m.entity.id
+				linked = linked || d.Start == m.start && d.End == m.end
+			}
+		}
+		if linked {
+			continue
+		}
+		confidence := m.entity.confidence * variantWeights[m.kind]
+		det := model.Detection{
+			EntityType:      "PERSON",
+			Text:            text[m.start:m.end],
+			Start:           m.start,
+			End:             m.end,
+			Confidence:      confidence,
+			DetectionMethod: "coreference",
+			Methods:         []string{"coreference"},
+			EntityID:        m.entity.id,
+		}
+		if explain {
+			det.Explanation = &model.Explanation{Source: "coreference", Pattern: "coreference/" + m.kind, BaseConfidence: confidence}
+		}
+		detections = append(detections, det)
+	}
+	return detections
+}
+
+// findEntity returns the entity a name refers to, or nil.
+func findEntity(entities []*corefEntity, first, last string) *corefEntity {
+	for _, e := range entities {
+		if e.first == first && e.last == last {
+			return e
+		}
+	}
+	if last != "" {
+		return nil
+	}
+	for _, e := range entities {
+		if e.last == "" {
+			continue
+		}
+		if first == e.last || first == e.first {
+			return e
+		}
+		for _, nick := range nicknames[e.first] {
+			if first == nick {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// closer reports whether a's first mention is closer before offset than b's.
+func closer(a, b *corefEntity, offset int) bool {
+	da, db := offset-a.start, offset-b.start
+	if (da >= 0) != (db >= 0) {
+		return da >= 0
+	}
+	if da >= 0 {
+		return da < db
+	}
+	return da > db
+}
+
+// splitName returns the first and last name in a detected name, dropping honorifics
+// and possessives. last is empty for single-word names.
+func splitName(name string) (first, last string) {
+	words := strings.Fields(name)
+	if len(words) > 0 && isHonorific(words[0]) {
+		words = words[1:]
+	}
+	for i, w := range words {
+		w = strings.TrimSuffix(strings.TrimSuffix(w, "'s"), "’s")
+		words[i] = strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) && r != '-' && r != '\'' })
+	}
+	if len(words) == 0 || !isCapitalized(words[0]) {
+		return "", ""
+	}
+	if len(words) == 1 {
+		return words[0], ""
+	}
+	if !isCapitalized(words[len(words)-1]) {
+		return "", ""
+	}
+	return words[0], words[len(words)-1]
+}
+
+func nameVariants(first, last string) []nameVariant {
+	variant := func(kind, expr string) nameVariant {
+		return nameVariant{kind: kind, pattern: regexp.MustCompile(`(` + expr + `)`)}
+	}
+	honorific := `(?:` + strings.Join(honorifics, "|") + `)\.?\s+`
+
+	var variants []nameVariant
+	if last == "" {
+		// A single-word name could be either a first name or a surname.
+		return append(variants,
+			variant("honorific", honorific+regexp.QuoteMeta(first)),
+			variant("full_name", regexp.QuoteMeta(first)),
+		)
+	}
+	variants = append(variants,
+		variant("full_name", regexp.QuoteMeta(first)+`\s+(?:\p{Lu}\.?\s+)?`+regexp.QuoteMeta(last)),
+		variant("honorific", honorific+regexp.QuoteMeta(last)),
+		variant("surname", regexp.QuoteMeta(last)),
+		variant("first_name", regexp.QuoteMeta(first)),
+	)
+	for _, nick := range nicknames[first] {
+		variants = append(variants, variant("nickname", regexp.QuoteMeta(nick)))
+	}
+	return variants
+}
+
+// otherSurname reports whether the word after text[:end] is a capitalized word other
+// than last, making the name before it someone else's.
+func otherSurname(text string, end int, last string) bool {
+	rest := strings.TrimLeft(text[end:], " \t")
+	if len(rest) == len(text[end:]) {
+		return false
+	}
+	if i := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsLetter(r) && r != '-' }); i >= 0 {
+		rest = rest[:i]
+	}
+	return rest != "" && isCapitalized(rest) && rest != last
+}
+
+// wordBoundary reports whether text[start:end] is not part of a longer word.
+func wordBoundary(text string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+	return true
+}
+
+func isHonorific(word string) bool {
+	word = strings.TrimSuffix(word, ".")
+	for _, h := range honorifics {
+		if strings.EqualFold(word, h) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCapitalized(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
@@ -0,0 +1,92 @@
+package detector
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+const maxCustomRegexLength = 1024
+
+// ErrInvalidPattern is returned for custom patterns that cannot be compiled.
+var ErrInvalidPattern = errors.New("invalid pattern")
+
+var customNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]{1,63}$`)
+
+// zeroWidthSamples are texts a custom regex is tried on to find zero-width matches,
+// such as those of `\b` or `x?`, which would report empty detections.
+var zeroWidthSamples = []string{"", "Employee EMP-004211 (jane.doe@acme.com) called 555-0100 on 2024-01-15, ref #A7."}
+
+// CompilePattern validates a tenant-defined pattern and compiles it for the RegexDetector.
+// Go's RE2 engine matches in linear time, so tenant regexes cannot cause catastrophic backtracking.
+func CompilePattern(p model.CustomPattern) (RegexPattern, error) {
+	if !customNamePattern.MatchString(p.Name) {
+		return RegexPattern{}, fmt.Errorf("%w: name must be upper-case letters, digits and underscores", ErrInvalidPattern)
+	}
+	if isBuiltinEntityType(p.Name) {
+		return RegexPattern{}, fmt.Errorf("%w: %s is a built-in entity type", ErrInvalidPattern, p.Name)
+	}
+	if p.Regex == "" || len(p.Regex) > maxCustomRegexLength {
+		return RegexPattern{}, fmt.Errorf("%w: regex must be 1-%d characters", ErrInvalidPattern, maxCustomRegexLength)
+	}
+	re, err := regexp.Compile(p.Regex)
+	if err != nil {
+		return RegexPattern{}, fmt.Errorf("%w: %v", ErrInvalidPattern, err)
+	}
+	for _, sample := range zeroWidthSamples {
+		for _, m := range re.FindAllStringIndex(sample, -1) {
+			if m[0] == m[1] {
+				return RegexPattern{}, fmt.Errorf("%w: regex must not match empty text", ErrInvalidPattern)
+			}
+		}
+	}
+	if p.Confidence <= 0 || p.Confidence > 1 {
+		return RegexPattern{}, fmt.Errorf("%w: confidence must be in (0, 1]", ErrInvalidPattern)
+	}
+
+	compiled := RegexPattern{
+		ID:         "custom/" + p.Name,
+		Name:       p.Name,
+		Pattern:    re,
+		Confidence: p.Confidence,
+		Method:     "custom",
+	}
+	if p.Validator != "" {
+		validator, ok := validators[p.Validator]
+		if !ok {
+			return RegexPattern{}, fmt.Errorf("%w: unknown validator %q (available: %s)", ErrInvalidPattern, p.Validator, strings.Join(ValidatorNames(), ", "))
+		}
+		compiled.Validator = validator
+		compiled.ValidatorName = p.Validator
+	}
+	for _, k := range p.ContextKeywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			compiled.Keywords = append(compiled.Keywords, k)
+		}
+	}
+	return compiled, nil
+}
+
+// isBuiltinEntityType reports whether name is reported by a built-in detector or is a
+// category of the taxonomy. Custom patterns must not reuse these names, so their matches
+// are not mistaken for, or filtered and thresholded as, built-in ones.
+func isBuiltinEntityType(name string) bool {
+	for t, parent := range entityParents {
+		if name == t || name == parent {
+			return true
+		}
+	}
+	for _, packs := range []map[string][]RegexPattern{localePatterns, transcriptPatterns} {
+		for _, pack := range packs {
+			for _, p := range pack {
+				if p.Name == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
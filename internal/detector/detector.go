@@ -0,0 +1,164 @@
+package detector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+// Detector is a source of PII detections. The pipeline calls Detect concurrently with
+// other detectors, so implementations must be safe for concurrent use.
+type Detector interface {
+	// Name identifies the detector in the registry, logs and errors.
+	Name() string
+	Detect(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error)
+}
+
+// DetectorOptions control how the pipeline runs a registered detector.
+type DetectorOptions struct {
+	// Timeout bounds a single Detect call. Zero means no per-detector timeout.
+	Timeout time.Duration
+	// Required detectors fail the whole request when they error, time out or panic.
+	// Failures of optional detectors are logged and their results skipped.
+	Required bool
+	Disabled bool
+}
+
+type registration struct {
+	detector Detector
+	opts     DetectorOptions
+}
+
+// Registry holds the detectors a Pipeline fans out to, in registration order.
+// It is safe for concurrent use, so detectors can be added or toggled at runtime.
+type Registry struct {
+	mu            sync.RWMutex
+	registrations []registration
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a detector. Names must be unique.
+func (r *Registry) Register(d Detector, opts DetectorOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.indexLocked(d.Name()) >= 0 {
+		return fmt.Errorf("detector %q already registered", d.Name())
+	}
+	r.registrations = append(r.registrations, registration{detector: d, opts: opts})
+	return nil
+}
+
+// Unregister removes a detector and reports whether it was registered.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexLocked(name)
+	if i < 0 {
+		return false
+	}
+	r.registrations = append(r.registrations[:i:i], r.registrations[i+1:]...)
+	return true
+}
+
+// SetEnabled enables or disables a registered detector.
+func (r *Registry) SetEnabled(name string, enabled bool) error {
+	return r.update(name, func(opts *DetectorOptions) { opts.Disabled = !enabled })
+}
+
+// SetTimeout changes the per-call timeout of a registered detector.
+func (r *Registry) SetTimeout(name string, timeout time.Duration) error {
+	return r.update(name, func(opts *DetectorOptions) { opts.Timeout = timeout })
+}
+
+// Get returns a registered detector by name.
+func (r *Registry) Get(name string) (Detector, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if i := r.indexLocked(name); i >= 0 {
+		return r.registrations[i].detector, true
+	}
+	return nil, false
+}
+
+// Names lists the registered detectors in registration order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, len(r.registrations))
+	for i, reg := range r.registrations {
+		names[i] = reg.detector.Name()
+	}
+	return names
+}
+
+// enabled returns a snapshot of the enabled registrations.
+func (r *Registry) enabled() []registration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []registration
+	for _, reg := range r.registrations {
+		if !reg.opts.Disabled {
+			out = append(out, reg)
+		}
+	}
+	return out
+}
+
+func (r *Registry) update(name string, fn func(*DetectorOptions)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.indexLocked(name)
+	if i < 0 {
+		return fmt.Errorf("detector %q not registered", name)
+	}
+	fn(&r.registrations[i].opts)
+	return nil
+}
+
+func (r *Registry) indexLocked(name string) int {
+	for i, reg := range r.registrations {
+		if reg.detector.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// run calls the detector with its timeout applied, turning panics into errors.
+// A detector that ignores ctx is abandoned when the timeout expires; its late
+// result is discarded.
+func (reg registration) run(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+	if reg.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, reg.opts.Timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		detections []model.Detection
+		err        error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("%s detector panicked: %v", reg.detector.Name(), r)}
+			}
+		}()
+		detections, err := reg.detector.Detect(ctx, req)
+		done <- result{detections: detections, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.detections, res.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s detector: %w", reg.detector.Name(), ctx.Err())
+	}
+}
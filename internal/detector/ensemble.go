@@ -0,0 +1,148 @@
+package detector
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+// Ensemble configures how detections of the same span by several detectors are combined.
+type Ensemble struct {
+	Aggregation model.EnsembleAggregation
+	// Weights per detection method for weighted_vote. Methods without a weight count as 1.
+	Weights map[string]float64
+}
+
+func validateAggregation(a model.EnsembleAggregation) error {
+	switch a {
+	case "", model.NoisyOrAggregation, model.WeightedVoteAggregation, model.MaxAggregation:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown ensemble_aggregation %q", ErrInvalidRequest, a)
+}
+
+type ensembleGroup struct {
+	det        model.Detection
+	confidence map[string]float64 // Highest confidence per method
+	members    []model.Detection
+}
+
+// combine merges detections that cover exactly the same span with the same entity type,
+// or with one type a category of the other, into one detection listing every method
+// that found it. The merged detection takes the more specific type, and the primary
+// DetectionMethod is that of the most confident contributor.
+func combine(detections []model.Detection, e Ensemble) []model.Detection {
+	sorted := append([]model.Detection(nil), detections...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		if a.End != b.End {
+			return a.End < b.End
+		}
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		return a.DetectionMethod < b.DetectionMethod
+	})
+
+	var groups []*ensembleGroup
+	var span []*ensembleGroup // Groups sharing the current span
+	for _, det := range sorted {
+		if len(span) > 0 && (span[0].det.Start != det.Start || span[0].det.End != det.End) {
+			span = nil
+		}
+		var g *ensembleGroup
+		for _, candidate := range span {
+			if EntityIsA(det.EntityType, candidate.det.EntityType) || EntityIsA(candidate.det.EntityType, det.EntityType) {
+				g = candidate
+				break
+			}
+		}
+		if g == nil {
+			g = &ensembleGroup{det: det, confidence: make(map[string]float64)}
+			groups = append(groups, g)
+			span = append(span, g)
+		} else if det.EntityType != g.det.EntityType && EntityIsA(det.EntityType, g.det.EntityType) {
+			g.det.EntityType = det.EntityType
+		}
+		g.members = append(g.members, det)
+		if c, ok := g.confidence[det.DetectionMethod]; !ok || det.Confidence > c {
+			g.confidence[det.DetectionMethod] = det.Confidence
+		}
+	}
+
+	combined := make([]model.Detection, 0, len(groups))
+	for _, g := range groups {
+		det := g.det
+		det.Methods = make([]string, 0, len(g.confidence))
+		for m := range g.confidence {
+			det.Methods = append(det.Methods, m)
+		}
+		sort.Strings(det.Methods)
+		if len(det.Methods) > 1 {
+			det.Confidence = aggregate(g.confidence, e)
+		}
+		if det.Explanation != nil && len(g.members) > 1 {
+			det.Explanation = explainEnsemble(g, det.Confidence, e)
+		}
+		combined = append(combined, det)
+	}
+	return combined
+}
+
+func aggregate(confidence map[string]float64, e Ensemble) float64 {
+	switch e.Aggregation {
+	case model.MaxAggregation:
+		best := 0.0
+		for _, c := range confidence {
+			best = max(best, c)
+		}
+		return best
+	case model.WeightedVoteAggregation:
+		var sum, weights float64
+		for m, c := range confidence {
+			w, ok := e.Weights[m]
+			if !ok {
+				w = 1
+			}
+			sum += w * c
+			weights += w
+		}
+		if weights == 0 {
+			return 0
+		}
+		return sum / weights
+	default:
+		// Noisy-OR: the detection is wrong only if every contributing detector is wrong.
+		miss := 1.0
+		for _, c := range confidence {
+			miss *= 1 - c
+		}
+		return 1 - miss
+	}
+}
+
+// explainEnsemble copies the primary contributor's explanation and records the merge.
+func explainEnsemble(g *ensembleGroup, confidence float64, e Ensemble) *model.Explanation {
+	explanation := *g.det.Explanation
+	explanation.Contributors = nil
+	for _, m := range g.members {
+		c := model.Contributor{Method: m.DetectionMethod, EntityType: m.EntityType, Confidence: m.Confidence}
+		if m.Explanation != nil {
+			c.Pattern = m.Explanation.Pattern
+		}
+		explanation.Contributors = append(explanation.Contributors, c)
+	}
+	aggregation := e.Aggregation
+	if aggregation == "" {
+		aggregation = model.NoisyOrAggregation
+	}
+	explanation.Adjustments = append(append([]model.Adjustment(nil), explanation.Adjustments...), model.Adjustment{
+		Stage: "ensemble", Action: string(aggregation),
+		ConfidenceBefore: g.det.Confidence, ConfidenceAfter: confidence,
+	})
+	return &explanation
+}
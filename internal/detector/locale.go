@@ -0,0 +1,222 @@
+package detector
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+// AutoLocale asks the pipeline to identify the text's languages and pick locales for them.
+const AutoLocale = "auto"
+
+// languageLocales lists the locales of each language, the one used without a region
+// hint first.
+var languageLocales = map[string][]string{
+	"en": {"en-US", "en-GB", "en-IN", "en-CA", "en-AU", "en-SG"},
+	"fr": {"fr-FR", "fr-CA"},
+	"de": {"de-DE"},
+	"es": {"es-ES"},
+	"it": {"it-IT"},
+	"nl": {"nl-NL"},
+	"pt": {"pt-BR"},
+	"zh": {"zh-CN"},
+	"ja": {"ja-JP"},
+	"ko": {"ko-KR"},
+}
+
+// regionHints are lowercased phone prefixes, currency symbols and words that point to
+// a locale other than its language's first.
+var regionHints = map[string][]string{
+	"en-GB": {"+44", "£", "postcode", "nhs", "national insurance"},
+	"en-IN": {"+91", "₹", "aadhaar", "rupees", "pin code"},
+	"en-CA": {"canada", "province", "postal code", "social insurance"},
+	"en-AU": {"+61", "a$", "australia", "medicare", "tfn"},
+	"en-SG": {"+65", "s$", "singapore", "nric"},
+	"fr-CA": {"québec", "quebec", "canada", "assurance sociale"},
+}
+
+// stopwords are frequent short words that identify a language written in Latin script.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "my", "your", "please", "with", "for", "this", "you", "of", "to", "number", "call"},
+	"de": {"der", "die", "das", "und", "ist", "ich", "nicht", "mit", "bitte", "meine", "ihre", "sie", "für", "nummer", "ein"},
+	"fr": {"le", "la", "les", "et", "est", "je", "vous", "avec", "pour", "mon", "votre", "une", "des", "numéro", "merci"},
+	"es": {"el", "los", "las", "y", "es", "yo", "usted", "con", "para", "mi", "su", "una", "número", "gracias", "por"},
+	"it": {"il", "lo", "gli", "e", "è", "sono", "con", "per", "mio", "mia", "suo", "una", "numero", "grazie", "non"},
+	"nl": {"de", "het", "een", "en", "is", "ik", "niet", "met", "voor", "mijn", "uw", "u", "nummer", "bedankt", "van"},
+	"pt": {"o", "os", "as", "e", "é", "eu", "você", "com", "para", "meu", "minha", "uma", "número", "obrigado", "não"},
+}
+
+// resolveLocales returns the locales to run req with, primary first and without
+// duplicates. Locale comes before Locales, "auto" expands to the locales identified in
+// the text, unknown locales become en-US as they always have, and a request naming no
+// locale uses defaultLocale.
+func resolveLocales(req model.DetectionRequest, defaultLocale string) []string {
+	requested := req.Locales
+	if req.Locale != "" {
+		requested = append([]string{req.Locale}, requested...)
+	}
+	if len(requested) == 0 {
+		requested = []string{defaultLocale}
+	}
+
+	var locales []string
+	seen := make(map[string]bool)
+	add := func(locale string) {
+		if _, ok := localePatterns[locale]; !ok {
+			locale = "en-US"
+		}
+		if !seen[locale] {
+			seen[locale] = true
+			locales = append(locales, locale)
+		}
+	}
+	for _, locale := range requested {
+		if !strings.EqualFold(locale, AutoLocale) {
+			add(locale)
+			continue
+		}
+		identified := identifyLocales(req.Text, defaultLocale)
+		if len(identified) == 0 {
+			identified = []string{defaultLocale}
+		}
+		for _, l := range identified {
+			add(l)
+		}
+	}
+	return locales
+}
+
+// identifyLocales picks locales for the languages identified in text and the regions
+// hinted at in it. Without a hint, a language gets defaultLocale if it is one of its
+// locales, else its first.
+func identifyLocales(text, defaultLocale string) []string {
+	lower := strings.ToLower(text)
+	var locales []string
+	for _, lang := range identifyLanguages(text) {
+		candidates := languageLocales[lang]
+		hinted := false
+		for _, locale := range candidates[1:] {
+			for _, hint := range regionHints[locale] {
+				if containsWord(lower, hint) {
+					locales = append(locales, locale)
+					hinted = true
+					break
+				}
+			}
+		}
+		if !hinted {
+			fallback := candidates[0]
+			for _, locale := range candidates {
+				if locale == defaultLocale {
+					fallback = locale
+				}
+			}
+			locales = append(locales, fallback)
+		}
+	}
+	return locales
+}
+
+// identifyLanguages returns the ISO 639-1 codes of the languages text is written in,
+// most evident first. Chinese, Japanese and Korean are told apart by script; languages
+// written in Latin script by their stopwords. A language is only reported with at
+// least two stopwords and half as many as the most evident one, so a stray "die" in
+// English text does not make it German.
+func identifyLanguages(text string) []string {
+	scores := make(map[string]int)
+	var han, kana, hangul int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Han, r):
+			han++
+		}
+	}
+	// Japanese mixes kanji with kana; Chinese has no kana.
+	if kana > 0 {
+		scores["ja"] = kana + han
+	} else if han > 1 {
+		scores["zh"] = han
+	}
+	if hangul > 1 {
+		scores["ko"] = hangul
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	best := 0
+	latin := make(map[string]int)
+	for lang, list := range stopwords {
+		for _, w := range words {
+			for _, s := range list {
+				if w == s {
+					latin[lang]++
+					break
+				}
+			}
+		}
+		best = maxInt(best, latin[lang])
+	}
+	for lang, n := range latin {
+		if n >= 2 && 2*n >= best {
+			scores[lang] = n
+		}
+	}
+
+	langs := make([]string, 0, len(scores))
+	for lang := range scores {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if scores[langs[i]] != scores[langs[j]] {
+			return scores[langs[i]] > scores[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+	return langs
+}
+
+// containsWord reports whether s contains hint not as part of a longer word.
+func containsWord(s, hint string) bool {
+	for i := 0; ; {
+		j := strings.Index(s[i:], hint)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(hint)
+		if !letterAt(s, start-1) && !letterAt(s, end) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func letterAt(s string, i int) bool {
+	return i >= 0 && i < len(s) && isASCIILetter(s[i])
+}
+
+// localeLanguage returns the language of a locale, such as "de" for "de-DE".
+func localeLanguage(locale string) string {
+	lang, _, _ := strings.Cut(locale, "-")
+	return lang
+}
+
+// hasLanguage reports whether req runs a locale of lang. A request without locales
+// has not been resolved and counts as English.
+func hasLanguage(req model.DetectionRequest, lang string) bool {
+	if len(req.Locales) == 0 && (req.Locale == "" || strings.EqualFold(req.Locale, AutoLocale)) {
+		return lang == "en"
+	}
+	for _, locale := range append([]string{req.Locale}, req.Locales...) {
+		if localeLanguage(locale) == lang {
+			return true
+		}
+	}
+	return false
+}
@@ -4,48 +4,166 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/asoasis/pii-redaction-api/internal/model"
 	"github.com/jdkato/prose/v2"
 )
 
+// nerConfidence is the confidence reported for NER detections. prose v2 only exposes
+// the winning IOB label of each token, not its probability, so every label gets the
+// same score.
+const nerConfidence = 0.85
+
 type NERDetector struct{}
 
 func NewNERDetector() *NERDetector {
 	return &NERDetector{}
 }
 
-func (d *NERDetector) Detect(ctx context.Context, text string) ([]model.Detection, error) {
-	doc, err := prose.NewDocument(text)
+func (d *NERDetector) Name() string {
+	return "ner"
+}
+
+// Detect only runs on English text, since the prose model is trained on English.
+func (d *NERDetector) Detect(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+	if !hasLanguage(req, "en") {
+		return nil, nil
+	}
+	text := req.Text
+	doc, err := prose.NewDocument(text, prose.WithSegmentation(false))
 	if err != nil {
 		return nil, fmt.Errorf("NER processing failed: %w", err)
 	}
 
 	var detections []model.Detection
-	for _, ent := range doc.Entities() {
-		piiType := mapProseEntityToPII(ent.Label)
+	for _, m := range nerMentions(text, doc.Tokens()) {
+		piiType := mapProseEntityToPII(m.label)
 		if piiType == "" {
 			continue
 		}
+		det := model.Detection{
+			EntityType:      piiType,
+			Text:            text[m.start:m.end],
+			Start:           m.start,
+			End:             m.end,
+			Confidence:      nerConfidence,
+			DetectionMethod: "ner",
+		}
+		if req.Explain {
+			det.Explanation = &model.Explanation{Source: "ner", Pattern: "prose/" + m.label, BaseConfidence: nerConfidence}
+		}
+		detections = append(detections, det)
+	}
+	return detections, nil
+}
+
+type nerMention struct {
+	label      string
+	start, end int
+}
 
-		// prose doesn't provide offsets in a straightforward way in doc.Entities()
-		// We'll need to find the offset in the text. This is a bit naive but works for v1.
-		// A better way would be to iterate through tokens if prose provided token offsets.
-		start := strings.Index(text, ent.Text)
-		if start == -1 {
+// nerMentions groups labelled tokens into entity mentions with byte offsets in text.
+// Tokens are located by walking text in order, so every mention gets its own offsets.
+//
+// A mention starts at a token with a B- or I- label and takes its entity type from it.
+// Like prose's own chunker, it extends over adjacent labelled tokens even when their
+// type differs, and over unlabelled proper nouns following a proper noun, since the
+// model often tags only the first word of a multi-word name.
+func nerMentions(text string, tokens []prose.Token) []nerMention {
+	clean, origin := sanitizeForProse(text)
+
+	var mentions []nerMention
+	var current *nerMention
+	prevTag, prevEnd := "", 0
+	flush := func() {
+		if current != nil {
+			mentions = append(mentions, *current)
+			current = nil
+		}
+	}
+
+	cursor := 0
+	for _, tok := range tokens {
+		i := strings.Index(clean[cursor:], tok.Text)
+		if i < 0 || tok.Text == "" {
+			// The token does not appear in the text as-is; end any open mention.
+			flush()
 			continue
 		}
+		start, end := cursor+i, cursor+i+len(tok.Text)
+		cursor = end
+		if current != nil && strings.TrimSpace(clean[prevEnd:start]) != "" {
+			flush()
+		}
 
-		detections = append(detections, model.Detection{
-			EntityType:      piiType,
-			Text:            ent.Text,
-			Start:           start,
-			End:             start + len(ent.Text),
-			Confidence:      0.85, // NER base confidence
-			DetectionMethod: "ner",
-		})
+		_, label, labelled := strings.Cut(tok.Label, "-")
+		properNoun := strings.HasPrefix(tok.Tag, "NNP") && strings.IndexFunc(tok.Text, unicode.IsLetter) >= 0
+		switch {
+		case current != nil && (labelled || properNoun && strings.HasPrefix(prevTag, "NNP")):
+			current.end = origin.end(end)
+		case labelled:
+			flush()
+			current = &nerMention{label: label, start: origin.start(start), end: origin.end(end)}
+		default:
+			flush()
+		}
+		prevTag, prevEnd = tok.Tag, end
 	}
-	return detections, nil
+	flush()
+	return mentions
+}
+
+// proseReplacements mirrors the substitutions prose's tokenizer makes before splitting
+// text, so token text can be found again.
+var proseReplacements = []struct{ from, to string }{
+	{"“", `"`},
+	{"”", `"`},
+	{"‘", "'"},
+	{"’", "'"},
+	{"&rsquo;", "'"},
+}
+
+// offsetMap maps byte offsets in sanitized text back to the original text.
+type offsetMap struct {
+	starts, ends []int // Original span of the unit each sanitized byte came from
+	length       int
+}
+
+func (m offsetMap) start(i int) int {
+	if i >= len(m.starts) {
+		return m.length
+	}
+	return m.starts[i]
+}
+
+func (m offsetMap) end(i int) int {
+	if i == 0 {
+		return m.start(0)
+	}
+	return m.ends[i-1]
+}
+
+// sanitizeForProse applies proseReplacements and records where each byte came from.
+func sanitizeForProse(text string) (string, offsetMap) {
+	var b strings.Builder
+	m := offsetMap{length: len(text)}
+	for i := 0; i < len(text); {
+		unit, replacement := 1, text[i:i+1]
+		for _, r := range proseReplacements {
+			if strings.HasPrefix(text[i:], r.from) {
+				unit, replacement = len(r.from), r.to
+				break
+			}
+		}
+		b.WriteString(replacement)
+		for range len(replacement) {
+			m.starts = append(m.starts, i)
+			m.ends = append(m.ends, i+unit)
+		}
+		i += unit
+	}
+	return b.String(), m
 }
 
 func mapProseEntityToPII(label string) string {
@@ -0,0 +1,208 @@
+package detector
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"golang.org/x/text/unicode/norm"
+)
+
+// homoglyphs maps Cyrillic and Greek letters to the Latin letters they look like.
+var homoglyphs = map[rune]rune{
+	'а': 'a', 'в': 'b', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x', 'і': 'i', 'ј': 'j', 'ѕ': 's', 'ԁ': 'd', 'ӏ': 'l',
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T', 'Х': 'X', 'У': 'Y', 'І': 'I', 'Ј': 'J', 'Ѕ': 'S',
+	'α': 'a', 'ο': 'o', 'ρ': 'p', 'ν': 'v', 'ι': 'i', 'κ': 'k', 'τ': 't', 'υ': 'u',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Χ': 'X', 'Υ': 'Y',
+}
+
+// deobfuscation rules replace capture group 1 of pattern with to, e.g. " [at] " with "@".
+// Rules marked transcript only apply to transcripts.
+var deobfuscation = []struct {
+	pattern    *regexp.Regexp
+	to         string
+	transcript bool
+}{
+	{pattern: regexp.MustCompile(`(\s*[\[({<]\s*(?i:at)\s*[\])}>]\s*)`), to: "@"},
+	{pattern: regexp.MustCompile(`(\s*[\[({<]\s*(?i:dot)\s*[\])}>]\s*)`), to: "."},
+	// Unbracketed "john at acme dot com" only counts when "dot" is spelled out too and it
+	// ends in a common TLD: "visit us at acme.com" is prose.
+	{pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-](\s+(?i:at)\s+)[A-Za-z0-9\-]+\s+(?i:dot)\s+(?i:com|net|org|edu|gov|io|co|uk|us|de|fr|ca|au)\b`), to: "@"},
+	// Speech recognition often writes the domain as "acme.com", so transcripts accept
+	// "john at acme.com".
+	{pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-](\s+(?i:at)\s+)[A-Za-z0-9\-]+\.(?i:com|net|org|edu|gov|io|co|uk|us|de|fr|ca|au)\b`), to: "@", transcript: true},
+	{pattern: regexp.MustCompile(`[@.][A-Za-z0-9\-]+(\s+(?i:dot)\s+)[A-Za-z]{2,}\b`), to: "."},
+}
+
+// spacedDigits matches runs of at least three single digits separated by spaces or by
+// dashes and dots with optional spaces around them, e.g. "1 2 3 - 4 5".
+var spacedDigits = regexp.MustCompile(`\b\d(?:(?:[ \t]*[-.][ \t]*|[ \t]+)\d\b){2,}`)
+
+// normalizeText rewrites text so obfuscated PII matches the regex patterns: NFKC
+// folds fullwidth and other compatibility forms, invisible characters are removed,
+// homoglyphs in words that also contain ASCII letters or digits become Latin, spelled
+// out "@" and "." are replaced and spaced-out digits are collapsed. The offsetMap maps
+// byte offsets in the result back to text. With transcript, spoken forms are parsed
+// before the deobfuscation rules see them.
+func normalizeText(text string, transcript bool) (string, offsetMap) {
+	out, m := foldUnicode(text, identityMap(len(text)))
+	out, m = foldHomoglyphs(out, m)
+	if transcript {
+		out, m = parseSpokenForms(out, m)
+	}
+	for _, rule := range deobfuscation {
+		if rule.transcript && !transcript {
+			continue
+		}
+		// Matches may not overlap, so repeat until a rule stops matching, as in
+		// "a at b dot co dot uk".
+		for i := 0; i < 4 && rule.pattern.MatchString(out); i++ {
+			out, m = replaceGroup(out, m, rule.pattern, rule.to)
+		}
+	}
+	return collapseDigits(out, m)
+}
+
+// prepareText returns the text patterns are matched against, with an offsetMap back
+// to text when it differs: normalized text, or with transcript only the spoken forms
+// parsed.
+func prepareText(text string, normalize, transcript bool) (string, offsetMap) {
+	switch {
+	case normalize:
+		return normalizeText(text, transcript)
+	case transcript:
+		return parseSpokenForms(text, identityMap(len(text)))
+	}
+	return text, offsetMap{}
+}
+
+// mapToOriginal moves detections found on normalized text back to text.
+func mapToOriginal(text string, detections []model.Detection, m offsetMap) []model.Detection {
+	for i := range detections {
+		d := &detections[i]
+		start, end := m.start(d.Start), m.end(d.End)
+		if d.Explanation != nil && d.Text != text[start:end] {
+			d.Explanation.Normalized = d.Text
+		}
+		d.Start, d.End, d.Text = start, end, text[start:end]
+	}
+	return detections
+}
+
+// rewriter builds normalized text while tracking where each byte came from.
+type rewriter struct {
+	b   strings.Builder
+	m   offsetMap
+	src offsetMap // Maps the text being rewritten back to the original
+}
+
+// write appends s as the replacement for src bytes [start, end).
+func (w *rewriter) write(s string, start, end int) {
+	w.b.WriteString(s)
+	for range len(s) {
+		w.m.starts = append(w.m.starts, w.src.start(start))
+		w.m.ends = append(w.m.ends, w.src.end(end))
+	}
+}
+
+// copy appends src bytes [start, end) unchanged.
+func (w *rewriter) copy(text string, start, end int) {
+	for i := start; i < end; i++ {
+		w.write(text[i:i+1], i, i+1)
+	}
+}
+
+func (w *rewriter) result() (string, offsetMap) {
+	w.m.length = w.src.length
+	return w.b.String(), w.m
+}
+
+func identityMap(length int) offsetMap {
+	m := offsetMap{starts: make([]int, length), ends: make([]int, length), length: length}
+	for i := range length {
+		m.starts[i], m.ends[i] = i, i+1
+	}
+	return m
+}
+
+func foldUnicode(text string, src offsetMap) (string, offsetMap) {
+	w := rewriter{src: src}
+	var it norm.Iter
+	it.InitString(norm.NFKC, text)
+	for !it.Done() {
+		start := it.Pos()
+		segment := strings.Map(func(r rune) rune {
+			if isInvisible(r) {
+				return -1
+			}
+			return r
+		}, string(it.Next()))
+		if segment != "" {
+			w.write(segment, start, it.Pos())
+		}
+	}
+	return w.result()
+}
+
+func isInvisible(r rune) bool {
+	switch r {
+	case '\u034f', '\u115f', '\u1160', '\u3164', '\uffa0': // Grapheme joiner and Hangul fillers
+		return true
+	}
+	return unicode.Is(unicode.Cf, r)
+}
+
+func foldHomoglyphs(text string, src offsetMap) (string, offsetMap) {
+	w := rewriter{src: src}
+	for start := 0; start < len(text); {
+		end := strings.IndexFunc(text[start:], unicode.IsSpace)
+		if end < 0 {
+			end = len(text)
+		} else {
+			_, size := utf8.DecodeRuneInString(text[start+end:])
+			end += start + size
+		}
+		word := text[start:end]
+		mixed := strings.IndexFunc(word, func(r rune) bool { return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) }) >= 0
+		for i, r := range word {
+			size := utf8.RuneLen(r)
+			if latin, ok := homoglyphs[r]; ok && mixed {
+				w.write(string(latin), start+i, start+i+size)
+			} else {
+				w.copy(text, start+i, start+i+size)
+			}
+		}
+		start = end
+	}
+	return w.result()
+}
+
+func replaceGroup(text string, src offsetMap, pattern *regexp.Regexp, to string) (string, offsetMap) {
+	w := rewriter{src: src}
+	last := 0
+	for _, m := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		w.copy(text, last, m[2])
+		w.write(to, m[2], m[3])
+		last = m[3]
+	}
+	w.copy(text, last, len(text))
+	return w.result()
+}
+
+func collapseDigits(text string, src offsetMap) (string, offsetMap) {
+	w := rewriter{src: src}
+	last := 0
+	for _, m := range spacedDigits.FindAllStringIndex(text, -1) {
+		w.copy(text, last, m[0])
+		for i := m[0]; i < m[1]; i++ {
+			if text[i] != ' ' && text[i] != '\t' {
+				w.copy(text, i, i+1)
+			}
+		}
+		last = m[1]
+	}
+	w.copy(text, last, len(text))
+	return w.result()
+}
@@ -0,0 +1,77 @@
+package detector
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+func validateOffsetUnit(u model.OffsetUnit) error {
+	switch u {
+	case "", model.ByteOffsets, model.RuneOffsets, model.UTF16Offsets:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown offset_unit %q", ErrInvalidRequest, u)
+}
+
+// OffsetConverter converts the byte offsets detectors work with into the unit a
+// client asked for.
+type OffsetConverter struct {
+	offsets []int // Offset in the requested unit of every byte offset; nil when they match
+}
+
+// NewOffsetConverter returns a converter for offsets into text.
+func NewOffsetConverter(text string, unit model.OffsetUnit) *OffsetConverter {
+	c := &OffsetConverter{}
+	if unit == "" || unit == model.ByteOffsets || isASCII(text) {
+		return c
+	}
+	c.offsets = make([]int, len(text)+1)
+	n := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		// Offsets inside a character map to its start.
+		for j := i; j < i+size; j++ {
+			c.offsets[j] = n
+		}
+		n++
+		if unit == model.UTF16Offsets && r >= 0x10000 {
+			n++ // Surrogate pair
+		}
+		i += size
+	}
+	c.offsets[len(text)] = n
+	return c
+}
+
+// Offset converts a byte offset.
+func (c *OffsetConverter) Offset(i int) int {
+	if c.offsets == nil || i < 0 || i >= len(c.offsets) {
+		return i
+	}
+	return c.offsets[i]
+}
+
+// Detections converts the offsets of detections in place.
+func (c *OffsetConverter) Detections(detections []model.Detection) {
+	for i := range detections {
+		detections[i].Start, detections[i].End = c.Offset(detections[i].Start), c.Offset(detections[i].End)
+	}
+}
+
+// RedactionDetails converts the original offsets of redaction details in place.
+func (c *OffsetConverter) RedactionDetails(details []model.RedactionDetail) {
+	for i := range details {
+		details[i].OriginalStart, details[i].OriginalEnd = c.Offset(details[i].OriginalStart), c.Offset(details[i].OriginalEnd)
+	}
+}
+
+func isASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,146 @@
+package detector
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+// defaultEntityPriority ranks entity types for the priority strategy when the request
+// does not name its own order. Structured identifiers outrank free-text entities.
+var defaultEntityPriority = []string{
+	"CREDIT_CARD", "GOV_ID", "EMAIL", "IP_ADDRESS", "PHONE", "PERSON", "ORGANIZATION", "LOCATION", "DATE",
+}
+
+func validateOverlapStrategy(s model.OverlapStrategy) error {
+	switch s {
+	case "", model.HighestConfidenceOverlap, model.LongestOverlap, model.PriorityOverlap:
+		return nil
+	}
+	return fmt.Errorf("%w: unknown overlap_strategy %q", ErrInvalidRequest, s)
+}
+
+// resolveOverlaps picks a non-overlapping set of detections, preferring candidates by
+// strategy and breaking ties on fixed fields so the result never depends on detector
+// order. With includeNested, the losing candidates are kept too, each with Parent set
+// to the ID of the first winner it overlaps; otherwise they are returned as dropped.
+// The result is sorted by position.
+func resolveOverlaps(detections []model.Detection, req model.DetectionRequest) (resolved, dropped []model.Detection) {
+	priority := req.EntityPriority
+	if len(priority) == 0 {
+		priority = defaultEntityPriority
+	}
+	rank := func(d model.Detection) int {
+		for i, t := range priority {
+			if EntityIsA(d.EntityType, t) {
+				return i
+			}
+		}
+		return len(priority)
+	}
+
+	candidates := append([]model.Detection(nil), detections...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		la, lb := a.End-a.Start, b.End-b.Start
+		switch req.OverlapStrategy {
+		case model.LongestOverlap:
+			if la != lb {
+				return la > lb
+			}
+		case model.PriorityOverlap:
+			if ra, rb := rank(a), rank(b); ra != rb {
+				return ra < rb
+			}
+		}
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+		if la != lb {
+			return la > lb
+		}
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		if a.EntityType != b.EntityType {
+			return a.EntityType < b.EntityType
+		}
+		return a.DetectionMethod < b.DetectionMethod
+	})
+
+	strategy := req.OverlapStrategy
+	if strategy == "" {
+		strategy = model.HighestConfidenceOverlap
+	}
+	var winners, nested []model.Detection
+	for _, c := range candidates {
+		blocked := false
+		for i, w := range winners {
+			if c.Start < w.End && w.Start < c.End {
+				blocked = true
+				decide(&c, "overlap", "dropped", fmt.Sprintf("overlaps %s, preferred by %s", describe(w), strategy))
+				decide(&winners[i], "overlap", "kept", fmt.Sprintf("preferred over %s by %s", describe(c), strategy))
+				break
+			}
+		}
+		if blocked {
+			nested = append(nested, c)
+		} else {
+			winners = append(winners, c)
+		}
+	}
+	sortByPosition(winners)
+	if !req.IncludeNested {
+		return winners, nested
+	}
+
+	all := append(winners, nested...)
+	parents := make([]int, len(all)) // index in all of each detection's winner, -1 for winners
+	for i := range all {
+		parents[i] = -1
+		if i < len(winners) {
+			continue
+		}
+		for j, w := range winners {
+			if all[i].Start < w.End && w.Start < all[i].End {
+				parents[i] = j
+				break
+			}
+		}
+	}
+
+	// Number detections in position order, so IDs read left to right.
+	order := make([]int, len(all))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return before(all[order[i]], all[order[j]]) })
+	for n, i := range order {
+		all[i].ID = "d" + strconv.Itoa(n+1)
+	}
+	for i, p := range parents {
+		if p >= 0 {
+			all[i].Parent = all[p].ID
+		}
+	}
+	sortByPosition(all)
+	return all, nil
+}
+
+func describe(det model.Detection) string {
+	return fmt.Sprintf("%s [%d:%d]", det.EntityType, det.Start, det.End)
+}
+
+// sortByPosition orders detections by start, with enclosing spans first.
+func sortByPosition(detections []model.Detection) {
+	sort.SliceStable(detections, func(i, j int) bool { return before(detections[i], detections[j]) })
+}
+
+func before(a, b model.Detection) bool {
+	if a.Start != b.Start {
+		return a.Start < b.Start
+	}
+	return a.End > b.End
+}
@@ -0,0 +1,361 @@
+package detector
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed phone_numbering_plan.yaml
+var phoneNumberingPlanData []byte
+
+const (
+	// Numbers written with their country code are unambiguous; national numbers could
+	// be another kind of number in the right format.
+	internationalPhoneConfidence = 0.95
+	nationalPhoneConfidence      = 0.85
+
+	minPhoneDigits = 7
+	maxPhoneDigits = 15 // The E.164 maximum, including the country code
+)
+
+// phoneEntityTypes are the entity types of regions that have their own; numbers from
+// other regions are reported as PHONE.
+var phoneEntityTypes = map[string]string{"US": "PHONE_US", "GB": "PHONE_UK", "IN": "PHONE_IN"}
+
+// phonePatternIDs keep the explanation pattern IDs of the regexes the phone detector
+// replaced, so references to them still match. Other numbers are explained as
+// phone/<region>/<line type>.
+var phonePatternIDs = map[string]string{"PHONE_US": "en-US/PHONE_US", "PHONE_UK": "en-GB/PHONE_UK", "PHONE_IN": "en-IN/PHONE_IN"}
+
+var phoneKeywords = []string{
+	"phone", "call", "tel", "mobile", "cell", "fax", "whatsapp", "contact",
+	"telefon", "téléphone", "teléfono", "telefono", "móvil", "cellulare", "handy", "celular", "電話", "电话", "手机", "전화",
+}
+
+var phoneDigits = regexp.MustCompile(`\d+`)
+
+type phoneRegion struct {
+	Region         string `yaml:"region"`
+	CountryCode    string `yaml:"country_code"`
+	NationalPrefix string `yaml:"national_prefix"`
+	// NationalPrefixOptional is set where national numbers are often written without
+	// the trunk prefix.
+	NationalPrefixOptional bool `yaml:"national_prefix_optional"`
+	LineTypes              []struct {
+		Type    string `yaml:"type"`
+		Pattern string `yaml:"pattern"`
+		re      *regexp.Regexp
+	} `yaml:"line_types"`
+}
+
+// lineType returns the line type of a national significant number, or "" if the
+// region has no such number.
+func (r *phoneRegion) lineType(nsn string) string {
+	for _, t := range r.LineTypes {
+		if t.re.MatchString(nsn) {
+			return t.Type
+		}
+	}
+	return ""
+}
+
+// match validates national, the digits after the country code or as dialled within
+// the region, and returns its national significant number and line type.
+func (r *phoneRegion) match(national string, international bool) (string, string, bool) {
+	if r.NationalPrefix != "" && strings.HasPrefix(national, r.NationalPrefix) {
+		// After a country code, the trunk prefix is written as in "+44 (0)20".
+		nsn := national[len(r.NationalPrefix):]
+		if t := r.lineType(nsn); t != "" {
+			return nsn, t, true
+		}
+	}
+	if international || r.NationalPrefix == "" || r.NationalPrefixOptional {
+		if t := r.lineType(national); t != "" {
+			return national, t, true
+		}
+	}
+	return "", "", false
+}
+
+// phonePlan indexes the numbering plans by region and by country code.
+type phonePlan struct {
+	byRegion map[string]*phoneRegion
+	byCode   map[string][]*phoneRegion // In file order
+}
+
+var numberingPlan = mustParsePhonePlan(phoneNumberingPlanData)
+
+func mustParsePhonePlan(data []byte) phonePlan {
+	var regions []*phoneRegion
+	if err := yaml.Unmarshal(data, &regions); err != nil {
+		panic(fmt.Sprintf("built-in numbering plan is invalid: %v", err))
+	}
+	plan := phonePlan{byRegion: make(map[string]*phoneRegion), byCode: make(map[string][]*phoneRegion)}
+	for _, r := range regions {
+		for i := range r.LineTypes {
+			r.LineTypes[i].re = regexp.MustCompile(`^(?:` + r.LineTypes[i].Pattern + `)$`)
+		}
+		plan.byRegion[r.Region] = r
+		plan.byCode[r.CountryCode] = append(plan.byCode[r.CountryCode], r)
+	}
+	return plan
+}
+
+type phoneNumber struct {
+	region      *phoneRegion
+	nsn         string
+	lineType    string
+	dialledCode bool // Written with its country code
+}
+
+func (n phoneNumber) e164() string {
+	return "+" + n.region.CountryCode + n.nsn
+}
+
+// parseInternational parses digits that start with a country code.
+func (p phonePlan) parseInternational(digits string) (phoneNumber, bool) {
+	for l := 1; l <= 3 && l < len(digits); l++ {
+		for _, r := range p.byCode[digits[:l]] {
+			if nsn, t, ok := r.match(digits[l:], true); ok {
+				return phoneNumber{region: r, nsn: nsn, lineType: t, dialledCode: true}, true
+			}
+		}
+	}
+	return phoneNumber{}, false
+}
+
+// parseNational parses digits as dialled within one of regions, or within a region
+// sharing its country code, as the US and Canada do.
+func (p phonePlan) parseNational(digits string, regions []string) (phoneNumber, bool) {
+	for _, region := range regions {
+		home, ok := p.byRegion[region]
+		if !ok {
+			continue
+		}
+		for _, r := range p.byCode[home.CountryCode] {
+			if nsn, t, ok := r.match(digits, false); ok {
+				return phoneNumber{region: r, nsn: nsn, lineType: t}, true
+			}
+		}
+	}
+	return phoneNumber{}, false
+}
+
+// PhoneDetector finds phone numbers and validates them against the numbering plans of
+// the request's locales, or of the country code they are written with. Each detection
+// carries the number's E.164 form, country and line type in its Metadata.
+type PhoneDetector struct {
+	defaultLocale string
+
+	mu        sync.RWMutex
+	normalize bool
+}
+
+func NewPhoneDetector(locale string) *PhoneDetector {
+	if locale == "" {
+		locale = "en-US"
+	}
+	return &PhoneDetector{defaultLocale: locale, normalize: true}
+}
+
+func (d *PhoneDetector) Name() string {
+	return "phone"
+}
+
+// SetNormalization turns the normalization of obfuscated text before matching on or off.
+func (d *PhoneDetector) SetNormalization(enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.normalize = enabled
+}
+
+func (d *PhoneDetector) Detect(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+	locales := req.Locales
+	if len(locales) == 0 {
+		locale := req.Locale
+		if locale == "" || strings.EqualFold(locale, AutoLocale) {
+			locale = d.defaultLocale
+		}
+		locales = []string{locale}
+	}
+	regions := make([]string, 0, len(locales))
+	for _, locale := range locales {
+		_, region, _ := strings.Cut(locale, "-")
+		regions = append(regions, strings.ToUpper(region))
+	}
+
+	d.mu.RLock()
+	normalize := d.normalize
+	d.mu.RUnlock()
+
+	text := req.Text
+	normalized, offsets := prepareText(text, normalize, req.Transcript)
+	detections := findPhones(normalized, regions, req.Explain)
+	if normalized != text {
+		detections = mapToOriginal(text, detections, offsets)
+	}
+	return detections, nil
+}
+
+// phoneGroup is a run of digits in a phone number candidate. gap is the text between
+// it and the previous group.
+type phoneGroup struct {
+	start, end int
+	gap        string
+}
+
+// findPhones splits text into chains of digit groups joined by the separators phone
+// numbers are written with, and reports the longest valid numbers in each chain.
+func findPhones(text string, regions []string, explain bool) []model.Detection {
+	var detections []model.Detection
+	var chain []phoneGroup
+	flush := func() {
+		for i := 0; i < len(chain); {
+			j, n, ok := longestPhone(text, chain, i, regions)
+			if !ok {
+				i++
+				continue
+			}
+			detections = append(detections, phoneDetection(text, chain, i, j, n, explain))
+			i = j + 1
+		}
+		chain = chain[:0]
+	}
+	for _, m := range phoneDigits.FindAllStringIndex(text, -1) {
+		if len(chain) > 0 {
+			if gap := text[chain[len(chain)-1].end:m[0]]; isPhoneSeparator(gap) {
+				chain = append(chain, phoneGroup{start: m[0], end: m[1], gap: gap})
+				continue
+			}
+		}
+		flush()
+		if m[0] > 0 && isASCIILetter(text[m[0]-1]) || m[1] < len(text) && isASCIILetter(text[m[1]]) {
+			// Part of a word, such as an ID or a postcode.
+			continue
+		}
+		chain = append(chain, phoneGroup{start: m[0], end: m[1]})
+	}
+	flush()
+	return detections
+}
+
+// isPhoneSeparator reports whether gap can join two digit groups of a phone number,
+// as in "(415) 555-2671" or "020 7946 0958".
+func isPhoneSeparator(gap string) bool {
+	return len(gap) <= 3 && strings.Trim(gap, " -.()") == ""
+}
+
+// longestPhone finds the longest valid number made of chain[i:j+1]. Only the groups
+// that fit in the longest possible number are tried, so long runs of digit groups cost
+// linear time.
+func longestPhone(text string, chain []phoneGroup, i int, regions []string) (int, phoneNumber, bool) {
+	last, count := i-1, 0
+	for k := i; k < len(chain); k++ {
+		if count += chain[k].end - chain[k].start; count > maxPhoneDigits+2 {
+			break
+		}
+		last = k
+	}
+	for j := last; j >= i; j-- {
+		if n, ok := parsePhone(text, chain, i, j, regions); ok {
+			return j, n, true
+		}
+	}
+	return 0, phoneNumber{}, false
+}
+
+func parsePhone(text string, chain []phoneGroup, i, j int, regions []string) (phoneNumber, bool) {
+	// A number taken from a longer chain must be set apart from the rest by spaces, so
+	// the tail of a longer number is not reported.
+	if i > 0 && !apart(chain[i].gap) || j+1 < len(chain) && !apart(chain[j+1].gap) {
+		return phoneNumber{}, false
+	}
+	var digits strings.Builder
+	dots, dashes := false, false
+	for k := i; k <= j; k++ {
+		if k > i {
+			dots = dots || strings.Contains(chain[k].gap, ".")
+			dashes = dashes || strings.Contains(chain[k].gap, "-")
+		}
+		digits.WriteString(text[chain[k].start:chain[k].end])
+	}
+	// Dots and dashes together, as in "529.982.247-25", write other kinds of numbers.
+	if dots && dashes || digits.Len() < minPhoneDigits || digits.Len() > maxPhoneDigits+2 {
+		return phoneNumber{}, false
+	}
+
+	s := digits.String()
+	if plusBefore(text, chain[i].start) {
+		return numberingPlan.parseInternational(s)
+	}
+	if strings.HasPrefix(s, "00") {
+		if n, ok := numberingPlan.parseInternational(s[2:]); ok {
+			return n, true
+		}
+	}
+	return numberingPlan.parseNational(s, regions)
+}
+
+func apart(gap string) bool {
+	return strings.Trim(gap, " ") == ""
+}
+
+// plusBefore reports whether the digits at start follow a "+", as in "+44" or "+(44)".
+func plusBefore(text string, start int) bool {
+	prefix := strings.TrimRight(text[:start], " (")
+	return strings.HasSuffix(prefix, "+") && len(text[:start])-len(prefix) <= 2
+}
+
+func phoneDetection(text string, chain []phoneGroup, i, j int, n phoneNumber, explain bool) model.Detection {
+	start, end := chain[i].start, chain[j].end
+	if plusBefore(text, start) {
+		start = strings.LastIndex(text[:start], "+")
+	} else if start > 0 && text[start-1] == '(' {
+		start--
+	}
+	if end < len(text) && text[end] == ')' && strings.Contains(text[start:end], "(") {
+		end++
+	}
+
+	entityType, ok := phoneEntityTypes[n.region.Region]
+	if !ok {
+		entityType = "PHONE"
+	}
+	confidence := nationalPhoneConfidence
+	if n.dialledCode {
+		confidence = internationalPhoneConfidence
+	}
+	det := model.Detection{
+		EntityType:      entityType,
+		Text:            text[start:end],
+		Start:           start,
+		End:             end,
+		Confidence:      confidence,
+		DetectionMethod: "phone",
+		Metadata:        map[string]string{"e164": n.e164(), "country": n.region.Region, "line_type": n.lineType},
+	}
+	if explain {
+		pattern, ok := phonePatternIDs[entityType]
+		if !ok {
+			pattern = "phone/" + n.region.Region + "/" + n.lineType
+		}
+		det.Explanation = &model.Explanation{Source: "phone", Pattern: pattern, BaseConfidence: confidence}
+	}
+	if keyword := firstKeyword(keywordContext(text, start, end), phoneKeywords); keyword != "" {
+		det.Confidence = min(det.Confidence+keywordBoost, 1.0)
+		if explain {
+			det.Explanation.Adjustments = append(det.Explanation.Adjustments, model.Adjustment{
+				Stage: "pattern_keyword", Action: string(model.BoostAction), Keyword: keyword,
+				ConfidenceBefore: confidence, ConfidenceAfter: det.Confidence,
+			})
+		}
+	}
+	return det
+}
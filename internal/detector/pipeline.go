@@ -2,145 +2,295 @@ package detector
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sort"
 	"sync"
 
 	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+	"github.com/rs/zerolog/log"
 )
 
+// defaultConfidenceThreshold applies when neither the request nor the tenant policy sets one.
+const defaultConfidenceThreshold = 0.60
+
+// ErrInvalidRequest is returned by Detect for requests with invalid options.
+var ErrInvalidRequest = errors.New("invalid detection request")
+
 type Pipeline struct {
-	regex     *RegexDetector
-	ner       *NERDetector
-	context   *ContextAnalyzer
-	enableNER bool
+	registry      *Registry
+	context       *ContextAnalyzer
+	defaultLocale string
+
+	mu       sync.RWMutex
+	policies map[string]model.DetectionPolicy
+	ensemble Ensemble
+	coref    Coreference
 }
 
+// NewPipeline creates a pipeline with the built-in regex, phone and NER detectors registered.
+// The NER detector is registered disabled unless enableNER is set.
 func NewPipeline(defaultLocale string, enableNER bool) *Pipeline {
-	p := &Pipeline{
-		regex:     NewRegexDetector(defaultLocale),
-		context:   NewContextAnalyzer(),
-		enableNER: enableNER,
+	if defaultLocale == "" {
+		defaultLocale = "en-US"
 	}
-	if enableNER {
-		p.ner = NewNERDetector()
+	p := &Pipeline{
+		registry:      NewRegistry(),
+		context:       NewContextAnalyzer(),
+		defaultLocale: defaultLocale,
+		policies:      make(map[string]model.DetectionPolicy),
+		coref:         DefaultCoreference,
 	}
+	p.registry.Register(NewRegexDetector(defaultLocale), DetectorOptions{Required: true})
+	p.registry.Register(NewPhoneDetector(defaultLocale), DetectorOptions{Required: true})
+	p.registry.Register(NewNERDetector(), DetectorOptions{Disabled: !enableNER})
 	return p
 }
 
+// Registry returns the detectors the pipeline fans out to.
+func (p *Pipeline) Registry() *Registry {
+	return p.registry
+}
+
+// ContextAnalyzer returns the analyzer that applies context rules to detections.
+func (p *Pipeline) ContextAnalyzer() *ContextAnalyzer {
+	return p.context
+}
+
+// SetTenantPolicy replaces a tenant's detection policy. A zero policy removes it.
+func (p *Pipeline) SetTenantPolicy(tenantID string, policy model.DetectionPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if policy.ConfidenceThreshold == 0 && len(policy.EntityThresholds) == 0 {
+		delete(p.policies, tenantID)
+		return
+	}
+	p.policies[tenantID] = policy
+}
+
+// SetEnsemble sets how detections of the same span by several detectors are combined.
+func (p *Pipeline) SetEnsemble(e Ensemble) error {
+	if err := validateAggregation(e.Aggregation); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ensemble = e
+	return nil
+}
+
+// SetCoreference configures the stage that propagates detected names to their other mentions.
+func (p *Pipeline) SetCoreference(c Coreference) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.coref = c
+}
+
+func (p *Pipeline) coreference() Coreference {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.coref
+}
+
+func (p *Pipeline) ensembleFor(req model.DetectionRequest) Ensemble {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	e := p.ensemble
+	if req.EnsembleAggregation != "" {
+		e.Aggregation = req.EnsembleAggregation
+	}
+	return e
+}
+
+func (p *Pipeline) policy(tenantID string) model.DetectionPolicy {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.policies[tenantID]
+}
+
+// Detect returns the detections Analyze keeps.
 func (p *Pipeline) Detect(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
-	var (
-		regexResults []model.Detection
-		nerResults   []model.Detection
-		regexErr     error
-		nerErr       error
-		wg           sync.WaitGroup
-	)
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		defer func() {
-			if r := recover(); r != nil {
-				regexErr = fmt.Errorf("regex detector panicked: %v", r)
-			}
-		}()
-		regexResults, regexErr = p.regex.Detect(ctx, req.Text, req.Locale)
-	}()
+	res, err := p.Analyze(ctx, req)
+	return res.Detections, err
+}
+
+// Analyze runs every enabled detector over the text and refines, filters and resolves
+// their candidates. With req.Explain, each detection carries an Explanation and the
+// result lists the dropped candidates.
+func (p *Pipeline) Analyze(ctx context.Context, req model.DetectionRequest) (model.DetectionResult, error) {
+	var res model.DetectionResult
+	if err := validateOverlapStrategy(req.OverlapStrategy); err != nil {
+		return res, err
+	}
+	if err := validateAggregation(req.EnsembleAggregation); err != nil {
+		return res, err
+	}
+	if err := validateOffsetUnit(req.OffsetUnit); err != nil {
+		return res, err
+	}
+
+	// Detectors see the resolved locales, primary first, with "auto" expanded
+	res.Locales = resolveLocales(req, p.defaultLocale)
+	req.Locale, req.Locales = res.Locales[0], res.Locales
 
-	if p.enableNER && p.ner != nil {
+	registrations := p.registry.enabled()
+	results := make([][]model.Detection, len(registrations))
+	errs := make([]error, len(registrations))
+
+	var wg sync.WaitGroup
+	for i, reg := range registrations {
 		wg.Add(1)
-		go func() {
+		go func(i int, reg registration) {
 			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					nerErr = fmt.Errorf("ner detector panicked: %v", r)
-				}
-			}()
-			nerResults, nerErr = p.ner.Detect(ctx, req.Text)
-		}()
+			results[i], errs[i] = reg.run(ctx, req)
+		}(i, reg)
 	}
-
 	wg.Wait()
 
-	if regexErr != nil {
-		return nil, regexErr
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if registrations[i].opts.Required {
+			return res, err
+		}
+		log.Warn().Err(err).Str("detector", registrations[i].detector.Name()).Msg("Detector failed, skipping its results")
+		results[i] = nil
 	}
-	if nerErr != nil {
-		return nil, nerErr
+
+	var all []model.Detection
+	for i, set := range results {
+		for _, det := range set {
+			if det.DetectionMethod == "" {
+				det.DetectionMethod = registrations[i].detector.Name()
+			}
+			if req.Explain && det.Explanation == nil {
+				det.Explanation = &model.Explanation{Source: det.DetectionMethod, BaseConfidence: det.Confidence}
+			}
+			if isRejected(det) {
+				res.Dropped = append(res.Dropped, det)
+				continue
+			}
+			all = append(all, det)
+		}
 	}
 
-	// Merge
-	merged := mergeDetections(regexResults, nerResults)
+	// Combine agreeing detectors
+	all = combine(all, p.ensembleFor(req))
 
 	// Refine
-	refined := p.context.Refine(ctx, req.Text, merged)
+	refined, dropped := p.context.refine(ctx, req.Text, all)
+	res.Dropped = append(res.Dropped, dropped...)
+
+	// Propagate names to their other mentions
+	refined = propagateNames(req.Text, refined, p.coreference(), req.Explain)
 
 	// Filter by entity types if requested
 	if len(req.EntityTypes) > 0 {
-		refined = filterByEntityTypes(refined, req.EntityTypes)
+		refined, dropped = filterByEntityTypes(refined, req.EntityTypes)
+		res.Dropped = append(res.Dropped, dropped...)
 	}
 
 	// Filter by confidence threshold
-	threshold := req.ConfidenceThreshold
-	if threshold == 0 {
-		threshold = 0.60
-	}
-	refined = filterByConfidence(refined, threshold)
+	refined, dropped = filterByConfidence(refined, newThresholds(req, p.policy(tenant.FromContext(ctx))))
+	res.Dropped = append(res.Dropped, dropped...)
 
-	// Sort by start position
-	sort.Slice(refined, func(i, j int) bool {
-		return refined[i].Start < refined[j].Start
-	})
+	// Resolve overlaps; the result is sorted by start position
+	res.Detections, dropped = resolveOverlaps(refined, req)
+	res.Dropped = append(res.Dropped, dropped...)
 
-	return refined, nil
+	if !req.Explain {
+		res.Dropped = nil
+	}
+	sortByPosition(res.Dropped)
+	return res, nil
 }
 
-func mergeDetections(sets ...[]model.Detection) []model.Detection {
-	var all []model.Detection
-	for _, set := range sets {
-		all = append(all, set...)
+// isRejected reports whether a detector returned det only to explain why it was dropped.
+func isRejected(det model.Detection) bool {
+	if det.Explanation == nil {
+		return false
+	}
+	for _, d := range det.Explanation.Decisions {
+		if d.Outcome == "dropped" {
+			return true
+		}
+	}
+	return false
+}
+
+// decide records a decision on det if it is being explained.
+func decide(det *model.Detection, stage, outcome, reason string) {
+	if det.Explanation != nil {
+		det.Explanation.Decisions = append(det.Explanation.Decisions, model.Decision{Stage: stage, Outcome: outcome, Reason: reason})
 	}
+}
 
-	sort.Slice(all, func(i, j int) bool {
-		if all[i].Start == all[j].Start {
-			return all[i].End > all[j].End
+func filterByEntityTypes(detections []model.Detection, types []string) (kept, dropped []model.Detection) {
+	for _, det := range detections {
+		requested := false
+		for _, t := range types {
+			if EntityIsA(det.EntityType, t) {
+				requested = true
+				break
+			}
 		}
-		return all[i].Start < all[j].Start
-	})
-
-	var merged []model.Detection
-	for _, det := range all {
-		if len(merged) == 0 || det.Start >= merged[len(merged)-1].End {
-			merged = append(merged, det)
-		} else if det.Confidence > merged[len(merged)-1].Confidence {
-			merged[len(merged)-1] = det
+		if requested {
+			kept = append(kept, det)
+		} else {
+			decide(&det, "entity_filter", "dropped", det.EntityType+" is not in entity_types")
+			dropped = append(dropped, det)
 		}
 	}
-	return merged
+	return kept, dropped
 }
 
-func filterByEntityTypes(detections []model.Detection, types []string) []model.Detection {
-	typeMap := make(map[string]bool)
-	for _, t := range types {
-		typeMap[t] = true
+// thresholds resolves the confidence threshold for each entity type.
+type thresholds struct {
+	global   float64
+	byEntity map[string]float64
+}
+
+// newThresholds layers the request's thresholds over the tenant policy's.
+func newThresholds(req model.DetectionRequest, policy model.DetectionPolicy) thresholds {
+	t := thresholds{global: defaultConfidenceThreshold, byEntity: make(map[string]float64)}
+	if policy.ConfidenceThreshold > 0 {
+		t.global = policy.ConfidenceThreshold
+	}
+	if req.ConfidenceThreshold > 0 {
+		t.global = req.ConfidenceThreshold
+	}
+	for k, v := range policy.EntityThresholds {
+		t.byEntity[k] = v
+	}
+	for k, v := range req.EntityThresholds {
+		t.byEntity[k] = v
 	}
+	return t
+}
 
-	var filtered []model.Detection
-	for _, det := range detections {
-		if typeMap[det.EntityType] {
-			filtered = append(filtered, det)
+func (t thresholds) forEntity(entityType string) float64 {
+	if v, ok := t.byEntity[entityType]; ok {
+		return v
+	}
+	for _, a := range EntityAncestors(entityType) {
+		if v, ok := t.byEntity[a]; ok {
+			return v
 		}
 	}
-	return filtered
+	return t.global
 }
 
-func filterByConfidence(detections []model.Detection, threshold float64) []model.Detection {
-	var filtered []model.Detection
+func filterByConfidence(detections []model.Detection, t thresholds) (kept, dropped []model.Detection) {
 	for _, det := range detections {
+		threshold := t.forEntity(det.EntityType)
 		if det.Confidence >= threshold {
-			filtered = append(filtered, det)
+			decide(&det, "threshold", "kept", fmt.Sprintf("confidence %.2f meets threshold %.2f", det.Confidence, threshold))
+			kept = append(kept, det)
+		} else {
+			decide(&det, "threshold", "dropped", fmt.Sprintf("confidence %.2f is below threshold %.2f", det.Confidence, threshold))
+			dropped = append(dropped, det)
 		}
 	}
-	return filtered
+	return kept, dropped
 }
@@ -2,51 +2,218 @@ package detector
 
 import (
 	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+)
+
+const (
+	keywordWindow = 50
+	keywordBoost  = 0.10
 )
 
 type RegexDetector struct {
 	defaultLocale string
+	normalize     bool
+
+	mu             sync.RWMutex
+	tenantPatterns map[string][]RegexPattern
 }
 
 func NewRegexDetector(locale string) *RegexDetector {
 	if locale == "" {
 		locale = "en-US"
 	}
-	return &RegexDetector{defaultLocale: locale}
+	return &RegexDetector{
+		defaultLocale:  locale,
+		normalize:      true,
+		tenantPatterns: make(map[string][]RegexPattern),
+	}
 }
 
-func (d *RegexDetector) Detect(ctx context.Context, text string, locale string) ([]model.Detection, error) {
-	if locale == "" {
-		locale = d.defaultLocale
+func (d *RegexDetector) Name() string {
+	return "regex"
+}
+
+// SetTenantPatterns replaces a tenant's custom patterns. They take effect for the next
+// request; an empty slice removes them.
+func (d *RegexDetector) SetTenantPatterns(tenantID string, patterns []RegexPattern) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(patterns) == 0 {
+		delete(d.tenantPatterns, tenantID)
+		return
+	}
+	d.tenantPatterns[tenantID] = patterns
+}
+
+// SetNormalization turns the normalization of obfuscated text before matching on or off.
+func (d *RegexDetector) SetNormalization(enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.normalize = enabled
+}
+
+func (d *RegexDetector) Detect(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+	text, locales := req.Text, req.Locales
+	if len(locales) == 0 {
+		locale := req.Locale
+		if locale == "" {
+			locale = d.defaultLocale
+		}
+		locales = []string{locale}
 	}
 
-	patterns, ok := localePatterns[locale]
-	if !ok {
-		// Fallback to en-US if locale not found
-		patterns = localePatterns["en-US"]
+	var patterns, spoken []RegexPattern
+	for _, locale := range locales {
+		pack, ok := localePatterns[locale]
+		if !ok {
+			// Fallback to en-US if locale not found
+			pack = localePatterns["en-US"]
+		}
+		patterns = append(patterns, pack...)
+		spoken = append(spoken, transcriptPatterns[locale]...)
+	}
+	if len(locales) > 1 {
+		patterns = uniquePatterns(patterns)
 	}
 
+	d.mu.RLock()
+	custom := d.tenantPatterns[tenant.FromContext(ctx)]
+	normalize := d.normalize
+	d.mu.RUnlock()
+
+	// Patterns match normalized text; detections are mapped back to the original.
+	normalized, offsets := prepareText(text, normalize, req.Transcript)
+
+	detections := matchPatterns(normalized, patterns, req.Explain)
+	detections = append(detections, matchPatterns(normalized, custom, req.Explain)...)
+	if req.Transcript {
+		detections = append(detections, matchPatterns(normalized, spoken, req.Explain)...)
+	}
+	if normalized != text {
+		detections = mapToOriginal(text, detections, offsets)
+	}
+	return detections, nil
+}
+
+// MatchPatterns runs patterns over text, applying validators and keyword boosts.
+func MatchPatterns(text string, patterns []RegexPattern) []model.Detection {
+	return matchPatterns(text, patterns, false)
+}
+
+// matchPatterns is MatchPatterns with optional explanations. When explaining, matches
+// that fail validation are returned too, marked as dropped by the validator.
+func matchPatterns(text string, patterns []RegexPattern, explain bool) []model.Detection {
 	var detections []model.Detection
 	for _, p := range patterns {
 		matches := p.Pattern.FindAllStringIndex(text, -1)
 		for _, m := range matches {
-			matchText := text[m[0]:m[1]]
-			if p.Validator != nil && !p.Validator(matchText) {
+			if m[0] == m[1] {
 				continue
 			}
-
-			detections = append(detections, model.Detection{
+			if p.ExplicitBoundaries && !separated(text, m[0], m[1]) {
+				continue
+			}
+			matchText := text[m[0]:m[1]]
+			method := p.Method
+			if method == "" {
+				method = "regex"
+			}
+			det := model.Detection{
 				EntityType:      p.Name,
 				Text:            matchText,
 				Start:           m[0],
 				End:             m[1],
 				Confidence:      p.Confidence,
-				DetectionMethod: "regex",
-			})
+				DetectionMethod: method,
+			}
+			if explain {
+				det.Explanation = &model.Explanation{
+					Source:         method,
+					Pattern:        p.ID,
+					Validator:      p.ValidatorName,
+					BaseConfidence: p.Confidence,
+				}
+			}
+
+			if p.Validator != nil {
+				valid := p.Validator(matchText)
+				if explain {
+					det.Explanation.ValidatorResult = "passed"
+					if !valid {
+						det.Explanation.ValidatorResult = "failed"
+						det.Explanation.Decisions = append(det.Explanation.Decisions, model.Decision{
+							Stage: "validator", Outcome: "dropped", Reason: p.ValidatorName + " validation failed",
+						})
+						detections = append(detections, det)
+					}
+				}
+				if !valid {
+					continue
+				}
+			}
+
+			if keyword := firstKeyword(keywordContext(text, m[0], m[1]), p.Keywords); keyword != "" {
+				det.Confidence = min(det.Confidence+keywordBoost, 1.0)
+				if explain {
+					det.Explanation.Adjustments = append(det.Explanation.Adjustments, model.Adjustment{
+						Stage: "pattern_keyword", Action: string(model.BoostAction), Keyword: keyword,
+						ConfidenceBefore: p.Confidence, ConfidenceAfter: det.Confidence,
+					})
+				}
+			}
+
+			detections = append(detections, det)
 		}
 	}
+	return detections
+}
 
-	return detections, nil
+// uniquePatterns drops the patterns several locale packs share, such as EMAIL and
+// IBAN, after their first occurrence.
+func uniquePatterns(patterns []RegexPattern) []RegexPattern {
+	seen := make(map[*regexp.Regexp]bool)
+	unique := patterns[:0]
+	for _, p := range patterns {
+		if !seen[p.Pattern] {
+			seen[p.Pattern] = true
+			unique = append(unique, p)
+		}
+	}
+	return unique
+}
+
+// keywordContext returns the lowercased text around a match, excluding the match itself.
+// The window is widened to whole characters so it never splits a multi-byte one.
+func keywordContext(text string, start, end int) string {
+	from := maxInt(0, start-keywordWindow)
+	for from > 0 && !utf8.RuneStart(text[from]) {
+		from--
+	}
+	to := minInt(len(text), end+keywordWindow)
+	for to < len(text) && !utf8.RuneStart(text[to]) {
+		to++
+	}
+	return strings.ToLower(text[from:start] + " " + text[end:to])
 }
+
+// separated reports whether text[start:end] does not continue the ASCII digit or letter
+// run at either of its ends.
+func separated(text string, start, end int) bool {
+	sameKind := func(a, b byte) bool {
+		return isASCIIDigit(a) && isASCIIDigit(b) || isASCIILetter(a) && isASCIILetter(b)
+	}
+	if start > 0 && sameKind(text[start-1], text[start]) {
+		return false
+	}
+	return end >= len(text) || !sameKind(text[end-1], text[end])
+}
+
+func isASCIIDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isASCIILetter(c byte) bool { return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' }
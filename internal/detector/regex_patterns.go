@@ -1,49 +1,149 @@
 package detector
 
-import "regexp"
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 type RegexPattern struct {
-	Name       string
-	Pattern    *regexp.Regexp
-	Validator  func(match string) bool
-	Confidence float64
+	ID        string // Reported in explanations; defaults to <locale>/<Name> for built-ins
+	Name      string
+	Pattern   *regexp.Regexp
+	Validator func(match string) bool
+	// ValidatorName names Validator in explanations.
+	ValidatorName string
+	Confidence    float64
+	// Keywords boost confidence when one of them appears near the match.
+	Keywords []string
+	// ExplicitBoundaries is set on patterns written without \b. Their matches only need
+	// to differ from the neighbouring characters: a number must not touch other digits
+	// and a letter must not touch other letters, so IDs run into CJK text or a label,
+	// as in "身份证号码ID110105...", still match.
+	ExplicitBoundaries bool
+	// Method is the detection method reported for matches; "regex" when empty.
+	Method string
 }
 
-// Locale-specific pattern maps.
+// validators is the checksum and format validator library custom patterns can refer to by name.
+// Custom patterns can pass them any match, so each checks the length and characters of
+// its input before reading it.
+var validators = map[string]func(match string) bool{
+	"luhn": luhnCheck,
+	"ssn":  validateSSN,
+	"ipv4": validateIPv4,
+
+	"uk_nino":     validateNINO,
+	"uk_nhs":      validateNHSNumber,
+	"uk_postcode": validateUKPostcode,
+
+	"iban":               validateIBAN,
+	"bic":                validateBIC,
+	"eu_vat":             validateEUVAT,
+	"de_tax_id":          validateSteuerID,
+	"fr_nir":             validateNIR,
+	"es_dni":             validateDNI,
+	"it_fiscal_code":     validateCodiceFiscale,
+	"nl_bsn":             validateBSN,
+	"uk_phone":           validateUKPhone,
+	"uk_driving_licence": validateUKDrivingLicence,
+
+	"verhoeff":    validateAadhaar,
+	"gstin":       validateGSTIN,
+	"in_mobile":   validateIndianMobile,
+	"upi":         validateUPIHandle,
+	"ca_sin":      validateSIN,
+	"au_tfn":      validateTFN,
+	"au_medicare": validateMedicare,
+	"au_abn":      validateABN,
+	"br_cpf":      validateCPF,
+	"br_cnpj":     validateCNPJ,
+
+	"cn_resident_id": validateChineseResidentID,
+	"jp_my_number":   validateMyNumber,
+	"kr_rrn":         validateRRN,
+	"sg_nric":        validateNRIC,
+}
+
+// ValidatorNames lists the validators available to custom patterns.
+func ValidatorNames() []string {
+	names := make([]string, 0, len(validators))
+	for name := range validators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Patterns that do not depend on the locale.
+var (
+	emailPattern = RegexPattern{
+		Name:       "EMAIL",
+		Pattern:    regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`),
+		Confidence: 0.99,
+	}
+	creditCardPattern = RegexPattern{
+		Name:          "CREDIT_CARD",
+		Pattern:       regexp.MustCompile(`\b(?:\d[ -]?){13,19}\b`),
+		Validator:     luhnCheck,
+		ValidatorName: "luhn",
+		Confidence:    0.97,
+	}
+	ipAddressPattern = RegexPattern{
+		Name:          "IP_ADDRESS",
+		Pattern:       regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`),
+		Validator:     validateIPv4,
+		ValidatorName: "ipv4",
+		Confidence:    0.92,
+	}
+)
+
+// Locale-specific pattern maps. The European locales also get the EU patterns.
 var localePatterns = map[string][]RegexPattern{
 	"en-US": {
+		emailPattern,
 		{
-			Name:       "EMAIL",
-			Pattern:    regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`),
-			Confidence: 0.99,
-		},
-		{
-			Name:       "SSN",
-			Pattern:    regexp.MustCompile(`\b(\d{3}-\d{2}-\d{4})\b`),
-			Validator:  validateSSN,
-			Confidence: 0.95,
-		},
-		{
-			Name:       "CREDIT_CARD",
-			Pattern:    regexp.MustCompile(`\b(?:\d[ -]?){13,19}\b`),
-			Validator:  luhnCheck,
-			Confidence: 0.97,
-		},
-		{
-			Name:       "PHONE_US",
-			Pattern:    regexp.MustCompile(`\b(?:\+?1[\s\-.]?)?\(?\d{3}\)?[\s\-.]?\d{3}[\s\-.]?\d{4}\b`),
-			Confidence: 0.90,
-		},
-		{
-			Name:       "IP_ADDRESS",
-			Pattern:    regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`),
-			Validator:  validateIPv4,
-			Confidence: 0.92,
+			Name:          "SSN",
+			Pattern:       regexp.MustCompile(`\b(\d{3}-\d{2}-\d{4})\b`),
+			Validator:     validateSSN,
+			ValidatorName: "ssn",
+			Confidence:    0.95,
 		},
+		creditCardPattern,
+		ipAddressPattern,
 	},
+	"en-GB": append(enGBPatterns, euPatterns...),
+	"de-DE": append(deDEPatterns, euPatterns...),
+	"fr-FR": append(frFRPatterns, euPatterns...),
+	"es-ES": append(esESPatterns, euPatterns...),
+	"it-IT": append(itITPatterns, euPatterns...),
+	"nl-NL": append(nlNLPatterns, euPatterns...),
+	"en-IN": enINPatterns,
+	"en-CA": enCAPatterns,
+	"fr-CA": append([]RegexPattern{}, enCAPatterns...), // A copy, so its IDs name fr-CA
+	"en-AU": enAUPatterns,
+	"pt-BR": ptBRPatterns,
+	"zh-CN": zhCNPatterns,
+	"ja-JP": jaJPPatterns,
+	"ko-KR": koKRPatterns,
+	"en-SG": enSGPatterns,
+}
+
+func init() {
+	for locale, patterns := range localePatterns {
+		for i := range patterns {
+			if patterns[i].ID == "" {
+				patterns[i].ID = locale + "/" + patterns[i].Name
+			}
+		}
+	}
 }
 
 func luhnCheck(cardNumber string) bool {
+	if !numberLike(cardNumber) {
+		return false
+	}
 	digits := []int{}
 	for _, r := range cardNumber {
 		if r >= '0' && r <= '9' {
@@ -69,14 +169,33 @@ func luhnCheck(cardNumber string) bool {
 	return sum%10 == 0
 }
 
+var ssnFormat = regexp.MustCompile(`^(\d{3})-?\d{2}-?\d{4}$`)
+
 func validateSSN(match string) bool {
-	// Simple validation: should not start with 000, 666, or 900-999.
-	// This is a basic example.
-	return !regexp.MustCompile(`^(000|666|9\d{2})`).MatchString(match)
+	// The area number is never 000, 666 or 900-999.
+	m := ssnFormat.FindStringSubmatch(match)
+	return m != nil && m[1] != "000" && m[1] != "666" && m[1][0] != '9'
 }
 
+// validateIPv4 checks that each of the four octets is a number up to 255.
 func validateIPv4(match string) bool {
-	// Basic regex already catches 4 groups of digits.
-	// We can add logic to check if each octet is <= 255.
-	return true // Placeholder
+	octets := strings.Split(match, ".")
+	if len(octets) != 4 {
+		return false
+	}
+	for _, o := range octets {
+		if o == "" || len(o) > 3 || len(onlyDigits(o)) != len(o) {
+			return false
+		}
+		if v, _ := strconv.Atoi(o); v > 255 {
+			return false
+		}
+	}
+	return true
+}
+
+// numberLike reports whether s has only ASCII digits and the separators numbers are
+// written with.
+func numberLike(s string) bool {
+	return strings.Trim(s, "0123456789 -./+()") == ""
 }
@@ -0,0 +1,144 @@
+package detector
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// East Asian IDs often run straight into the surrounding CJK text or a Latin label, so
+// these patterns use ExplicitBoundaries instead of \b. Fullwidth digits are folded to
+// ASCII by text normalization before matching.
+
+var zhCNPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:               "CN_RESIDENT_ID",
+		Pattern:            regexp.MustCompile(`[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]`),
+		ExplicitBoundaries: true,
+		Validator:          validateChineseResidentID,
+		ValidatorName:      "cn_resident_id",
+		Confidence:         0.95,
+		Keywords:           []string{"身份证", "身份号码", "证件号", "id"},
+	},
+}
+
+var jaJPPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:               "JP_MY_NUMBER",
+		Pattern:            regexp.MustCompile(`\d{4}[ -]?\d{4}[ -]?\d{4}`),
+		ExplicitBoundaries: true,
+		Validator:          validateMyNumber,
+		ValidatorName:      "jp_my_number",
+		Confidence:         0.65,
+		Keywords:           []string{"マイナンバー", "個人番号", "my number"},
+	},
+}
+
+var koKRPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:               "KR_RRN",
+		Pattern:            regexp.MustCompile(`\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])-?[1-8]\d{6}`),
+		ExplicitBoundaries: true,
+		Validator:          validateRRN,
+		ValidatorName:      "kr_rrn",
+		Confidence:         0.85,
+		Keywords:           []string{"주민등록번호", "주민번호", "rrn"},
+	},
+}
+
+var enSGPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:               "SG_NRIC",
+		Pattern:            regexp.MustCompile(`[STFGM]\d{7}[A-Z]`),
+		ExplicitBoundaries: true,
+		Validator:          validateNRIC,
+		ValidatorName:      "sg_nric",
+		Confidence:         0.90,
+		Keywords:           []string{"nric", "fin", "ic no", "身份证"},
+	},
+}
+
+// validateChineseResidentID checks the date of birth and the ISO 7064 mod 11-2 check
+// character of an 18 digit resident ID.
+func validateChineseResidentID(match string) bool {
+	id := strings.ToUpper(match)
+	if len(id) != 18 || len(onlyDigits(id[:17])) != 17 {
+		return false
+	}
+	if _, err := time.Parse("20060102", id[6:14]); err != nil {
+		return false
+	}
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	return "10X98765432"[weightedSum(digitValues(id[:17]), weights)%11] == id[17]
+}
+
+// validateMyNumber checks the check digit of a Japanese individual number.
+func validateMyNumber(match string) bool {
+	d := digitValues(onlyDigits(match))
+	if len(d) != 12 || !numberLike(match) {
+		return false
+	}
+	sum := 0
+	for n := 1; n <= 11; n++ {
+		q := n + 1
+		if n > 6 {
+			q = n - 5
+		}
+		sum += d[11-n] * q
+	}
+	check := 0
+	if r := sum % 11; r > 1 {
+		check = 11 - r
+	}
+	return check == d[11]
+}
+
+// validateRRN checks the date of birth of a Korean resident registration number, using
+// the century the seventh digit encodes. Numbers issued since October 2020 have no
+// check digit, so it is not verified.
+func validateRRN(match string) bool {
+	digits := onlyDigits(match)
+	if len(digits) != 13 || !numberLike(match) {
+		return false
+	}
+	century := map[byte]string{'1': "19", '2': "19", '3': "20", '4': "20", '5': "19", '6': "19", '7': "20", '8': "20"}[digits[6]]
+	_, err := time.Parse("20060102", century+digits[:6])
+	return err == nil
+}
+
+// validateNRIC checks the check letter of a Singapore NRIC or FIN. The letter table
+// depends on the prefix, as does an offset added to the weighted sum.
+func validateNRIC(match string) bool {
+	if len(match) != 9 || len(onlyDigits(match[1:8])) != 7 {
+		return false
+	}
+	sum := weightedSum(digitValues(match[1:8]), []int{2, 7, 6, 5, 4, 3, 2})
+	var letters string
+	switch match[0] {
+	case 'S', 'T':
+		letters = "JZIHGFEDCBA"
+	case 'F', 'G':
+		letters = "XWUTRQPNMLK"
+	case 'M':
+		letters = "XWUTRQPNJLK"
+		sum += 3
+	default:
+		return false
+	}
+	if match[0] == 'T' || match[0] == 'G' {
+		sum += 4
+	}
+	return letters[sum%11] == match[8]
+}
@@ -0,0 +1,70 @@
+package detector
+
+import "regexp"
+
+// enAUPatterns is the Australia locale pack.
+var enAUPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:          "AU_TFN",
+		Pattern:       regexp.MustCompile(`\b\d{3}[ -]?\d{3}[ -]?\d{2,3}\b`),
+		Validator:     validateTFN,
+		ValidatorName: "au_tfn",
+		Confidence:    0.65,
+		Keywords:      []string{"tfn", "tax file"},
+	},
+	{
+		Name:          "AU_MEDICARE",
+		Pattern:       regexp.MustCompile(`\b[2-6]\d{3} ?\d{5} ?\d(?:[ /-]?\d)?\b`),
+		Validator:     validateMedicare,
+		ValidatorName: "au_medicare",
+		Confidence:    0.70,
+		Keywords:      []string{"medicare"},
+	},
+	{
+		Name:          "AU_ABN",
+		Pattern:       regexp.MustCompile(`\b\d{2} ?\d{3} ?\d{3} ?\d{3}\b`),
+		Validator:     validateABN,
+		ValidatorName: "au_abn",
+		Confidence:    0.80,
+		Keywords:      []string{"abn", "business number"},
+	},
+}
+
+// validateTFN checks the weighted checksum of an eight or nine digit tax file number.
+func validateTFN(match string) bool {
+	if !numberLike(match) {
+		return false
+	}
+	d := digitValues(onlyDigits(match))
+	switch len(d) {
+	case 8:
+		return weightedSum(d, []int{10, 7, 8, 4, 6, 3, 5, 1})%11 == 0
+	case 9:
+		return weightedSum(d, []int{1, 4, 3, 7, 5, 8, 6, 9, 10})%11 == 0
+	}
+	return false
+}
+
+// validateMedicare checks the ninth digit of a Medicare number, which may be followed
+// by the issue number and the individual reference number.
+func validateMedicare(match string) bool {
+	d := digitValues(onlyDigits(match))
+	if len(d) != 10 && len(d) != 11 || !numberLike(match) {
+		return false
+	}
+	return weightedSum(d[:8], []int{1, 3, 7, 9, 1, 3, 7, 9})%10 == d[8]
+}
+
+// validateABN checks an Australian Business Number: with one subtracted from the
+// first digit, the weighted sum is a multiple of 89.
+func validateABN(match string) bool {
+	d := digitValues(onlyDigits(match))
+	if len(d) != 11 || d[0] == 0 || !numberLike(match) {
+		return false
+	}
+	d[0]--
+	return weightedSum(d, []int{10, 1, 3, 5, 7, 9, 11, 13, 15, 17, 19})%89 == 0
+}
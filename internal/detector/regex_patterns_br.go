@@ -0,0 +1,61 @@
+package detector
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ptBRPatterns is the Brazil locale pack.
+var ptBRPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:          "BR_CPF",
+		Pattern:       regexp.MustCompile(`\b\d{3}\.?\d{3}\.?\d{3}-?\d{2}\b`),
+		Validator:     validateCPF,
+		ValidatorName: "br_cpf",
+		Confidence:    0.90,
+		Keywords:      []string{"cpf", "contribuinte"},
+	},
+	{
+		Name:          "BR_CNPJ",
+		Pattern:       regexp.MustCompile(`\b\d{2}\.?\d{3}\.?\d{3}/?\d{4}-?\d{2}\b`),
+		Validator:     validateCNPJ,
+		ValidatorName: "br_cnpj",
+		Confidence:    0.90,
+		Keywords:      []string{"cnpj", "empresa"},
+	},
+}
+
+// validateCPF checks the two mod 11 check digits of a CPF. Numbers made of one
+// repeated digit pass the check but are never issued.
+func validateCPF(match string) bool {
+	digits := onlyDigits(match)
+	if len(digits) != 11 || !numberLike(match) || strings.Count(digits, digits[:1]) == 11 {
+		return false
+	}
+	d := digitValues(digits)
+	return mod11CheckDigit(d[:9], []int{10, 9, 8, 7, 6, 5, 4, 3, 2}) == d[9] &&
+		mod11CheckDigit(d[:10], []int{11, 10, 9, 8, 7, 6, 5, 4, 3, 2}) == d[10]
+}
+
+// validateCNPJ checks the two mod 11 check digits of a CNPJ.
+func validateCNPJ(match string) bool {
+	digits := onlyDigits(match)
+	if len(digits) != 14 || !numberLike(match) || strings.Count(digits, digits[:1]) == 14 {
+		return false
+	}
+	d := digitValues(digits)
+	return mod11CheckDigit(d[:12], []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == d[12] &&
+		mod11CheckDigit(d[:13], []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}) == d[13]
+}
+
+// mod11CheckDigit is 11 minus the weighted sum modulo 11, or 0 when that is 10 or 11.
+func mod11CheckDigit(d, weights []int) int {
+	check := 11 - weightedSum(d, weights)%11
+	if check >= 10 {
+		return 0
+	}
+	return check
+}
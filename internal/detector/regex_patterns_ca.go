@@ -0,0 +1,31 @@
+package detector
+
+import "regexp"
+
+// enCAPatterns is the Canada locale pack, used for fr-CA too.
+var enCAPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:          "CA_SIN",
+		Pattern:       regexp.MustCompile(`\b[1-79]\d{2}[ -]?\d{3}[ -]?\d{3}\b`),
+		Validator:     validateSIN,
+		ValidatorName: "ca_sin",
+		Confidence:    0.65,
+		Keywords:      []string{"sin", "social insurance", "nas", "assurance sociale"},
+	},
+	{
+		Name: "CA_POSTAL_CODE",
+		// D, F, I, O, Q and U are never used, nor W and Z as the first letter.
+		Pattern:    regexp.MustCompile(`\b[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d\b`),
+		Confidence: 0.85,
+		Keywords:   []string{"postal code", "code postal", "address", "adresse"},
+	},
+}
+
+// validateSIN applies the Luhn check to a Canadian social insurance number.
+func validateSIN(match string) bool {
+	digits := onlyDigits(match)
+	return len(digits) == 9 && numberLike(match) && luhnValid(digits)
+}
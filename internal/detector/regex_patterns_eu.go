@@ -0,0 +1,619 @@
+package detector
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// euPatterns find European payment and tax identifiers. They are part of the European
+// locale packs; other locales add them by listing a European locale in locales.
+var euPatterns = []RegexPattern{
+	{
+		Name:          "IBAN",
+		Pattern:       regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`),
+		Validator:     validateIBAN,
+		ValidatorName: "iban",
+		// Above CREDIT_CARD, so an IBAN wins over card numbers found among its digits.
+		Confidence: 0.98,
+		Keywords:   []string{"iban", "bank", "account"},
+	},
+	{
+		Name:          "SWIFT_BIC",
+		Pattern:       regexp.MustCompile(`\b[A-Z]{4}[A-Z]{2}[A-Z2-9][A-NP-Z0-9](?:[A-Z0-9]{3})?\b`),
+		Validator:     validateBIC,
+		ValidatorName: "bic",
+		// Any eight or eleven capital letters fit the format, such as HOSPITAL, so the
+		// swift-bic-keyword-boost context rule must lift it to the default threshold.
+		Confidence: 0.50,
+	},
+	{
+		Name:          "EU_VAT",
+		Pattern:       regexp.MustCompile(`\b(?:AT|BE|BG|CY|CZ|DE|DK|EE|EL|ES|FI|FR|HR|HU|IE|IT|LT|LU|LV|MT|NL|PL|PT|RO|SE|SI|SK) ?[0-9A-Z+*]{2,13}\b`),
+		Validator:     validateEUVAT,
+		ValidatorName: "eu_vat",
+		Confidence:    0.90,
+		Keywords:      []string{"vat", "tax", "btw", "tva", "iva", "ust", "mwst", "moms", "alv"},
+	},
+}
+
+var deDEPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:          "DE_TAX_ID",
+		Pattern:       regexp.MustCompile(`\b[1-9]\d(?: ?\d{3}){3}\b`),
+		Validator:     validateSteuerID,
+		ValidatorName: "de_tax_id",
+		Confidence:    0.65,
+		Keywords:      []string{"steuer", "identifikationsnummer", "idnr", "tax id", "tin"},
+	},
+}
+
+var frFRPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:          "FR_NIR",
+		Pattern:       regexp.MustCompile(`\b[12] ?\d{2} ?(?:0[1-9]|1[0-2]|[2-9]\d) ?(?:\d{2}|2[AB]) ?\d{3} ?\d{3} ?\d{2}\b`),
+		Validator:     validateNIR,
+		ValidatorName: "fr_nir",
+		Confidence:    0.90,
+		Keywords:      []string{"sécurité sociale", "securite sociale", "nir", "insee", "carte vitale"},
+	},
+}
+
+var esESPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:          "ES_DNI",
+		Pattern:       regexp.MustCompile(`\b\d{8}-?[A-Z]\b`),
+		Validator:     validateDNI,
+		ValidatorName: "es_dni",
+		Confidence:    0.90,
+		Keywords:      []string{"dni", "nif", "documento"},
+	},
+	{
+		Name:          "ES_NIE",
+		Pattern:       regexp.MustCompile(`\b[XYZ]-?\d{7}-?[A-Z]\b`),
+		Validator:     validateDNI,
+		ValidatorName: "es_dni",
+		Confidence:    0.90,
+		Keywords:      []string{"nie", "extranjero"},
+	},
+}
+
+var itITPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name: "IT_FISCAL_CODE",
+		// Digits may be replaced with letters (omocodia) when two codes would collide.
+		Pattern:       regexp.MustCompile(`\b[A-Z]{6}[0-9LMNP-V]{2}[ABCDEHLMPRST][0-9LMNP-V]{2}[A-Z][0-9LMNP-V]{3}[A-Z]\b`),
+		Validator:     validateCodiceFiscale,
+		ValidatorName: "it_fiscal_code",
+		Confidence:    0.95,
+		Keywords:      []string{"codice fiscale", "c.f.", "cf"},
+	},
+}
+
+var nlNLPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:          "NL_BSN",
+		Pattern:       regexp.MustCompile(`\b\d{4}\.?\d{2}\.?\d{3}\b`),
+		Validator:     validateBSN,
+		ValidatorName: "nl_bsn",
+		Confidence:    0.55,
+		Keywords:      []string{"bsn", "burgerservicenummer", "sofinummer"},
+	},
+}
+
+// ibanLengths is the IBAN length of every country that uses one.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BR": 29,
+	"BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DK": 18, "DO": 28, "EE": 20, "EG": 29,
+	"ES": 24, "FI": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28,
+	"HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20,
+	"LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "MC": 27, "MD": 24, "ME": 22, "MK": 19,
+	"MR": 27, "MT": 31, "MU": 30, "NL": 18, "NO": 15, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29,
+	"RO": 24, "RS": 22, "SA": 24, "SC": 31, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20,
+}
+
+// validateIBAN checks the length for the country and the ISO 7064 mod 97 check digits.
+func validateIBAN(match string) bool {
+	iban := strings.ReplaceAll(match, " ", "")
+	if len(iban) < 4 || ibanLengths[iban[:2]] != len(iban) {
+		return false
+	}
+	return mod97(iban[4:]+iban[:4]) == 1
+}
+
+// mod97 returns s modulo 97, reading letters as 10 to 35 as IBANs do.
+func mod97(s string) int {
+	rem := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			rem = (rem*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			rem = (rem*100 + int(r-'A') + 10) % 97
+		default:
+			return -1
+		}
+	}
+	return rem
+}
+
+// bicCountries adds common non-IBAN countries to the IBAN ones for SWIFT codes.
+var bicCountries = []string{
+	"US", "CA", "MX", "BR", "AR", "CL", "CO", "PE", "AU", "NZ", "JP", "CN", "HK", "SG", "IN", "KR",
+	"TW", "TH", "MY", "ID", "PH", "VN", "ZA", "NG", "KE", "MA", "RU",
+}
+
+var bicFormat = regexp.MustCompile(`^[A-Z]{6}[A-Z0-9]{2}(?:[A-Z0-9]{3})?$`)
+
+// validateBIC checks that the country code of a SWIFT/BIC code exists.
+func validateBIC(match string) bool {
+	if !bicFormat.MatchString(match) {
+		return false
+	}
+	country := match[4:6]
+	if _, ok := ibanLengths[country]; ok {
+		return true
+	}
+	for _, c := range bicCountries {
+		if c == country {
+			return true
+		}
+	}
+	return false
+}
+
+// vatFormats is the format of each member state's VAT number, after the prefix.
+var vatFormats = map[string]*regexp.Regexp{
+	"AT": regexp.MustCompile(`^U\d{8}$`),
+	"BE": regexp.MustCompile(`^[01]\d{9}$`),
+	"BG": regexp.MustCompile(`^\d{9,10}$`),
+	"CY": regexp.MustCompile(`^\d{8}[A-Z]$`),
+	"CZ": regexp.MustCompile(`^\d{8,10}$`),
+	"DE": regexp.MustCompile(`^\d{9}$`),
+	"DK": regexp.MustCompile(`^\d{8}$`),
+	"EE": regexp.MustCompile(`^\d{9}$`),
+	"EL": regexp.MustCompile(`^\d{9}$`),
+	"ES": regexp.MustCompile(`^[A-Z0-9]\d{7}[A-Z0-9]$`),
+	"FI": regexp.MustCompile(`^\d{8}$`),
+	"FR": regexp.MustCompile(`^[A-HJ-NP-Z0-9]{2}\d{9}$`),
+	"HR": regexp.MustCompile(`^\d{11}$`),
+	"HU": regexp.MustCompile(`^\d{8}$`),
+	"IE": regexp.MustCompile(`^(?:\d{7}[A-W][A-I]?|\d[A-Z+*]\d{5}[A-W])$`),
+	"IT": regexp.MustCompile(`^\d{11}$`),
+	"LT": regexp.MustCompile(`^(?:\d{9}|\d{12})$`),
+	"LU": regexp.MustCompile(`^\d{8}$`),
+	"LV": regexp.MustCompile(`^\d{11}$`),
+	"MT": regexp.MustCompile(`^\d{8}$`),
+	"NL": regexp.MustCompile(`^\d{9}B\d{2}$`),
+	"PL": regexp.MustCompile(`^\d{10}$`),
+	"PT": regexp.MustCompile(`^\d{9}$`),
+	"RO": regexp.MustCompile(`^\d{2,10}$`),
+	"SE": regexp.MustCompile(`^\d{10}01$`),
+	"SI": regexp.MustCompile(`^\d{8}$`),
+	"SK": regexp.MustCompile(`^\d{10}$`),
+}
+
+// vatChecks verifies the check digits of each member state's VAT numbers.
+var vatChecks = map[string]func(number string) bool{
+	"AT": func(n string) bool {
+		d := digitValues(n[1:])
+		sum := d[0] + d[2] + d[4] + d[6]
+		for _, x := range []int{d[1], d[3], d[5]} {
+			sum += x/5 + (2*x)%10
+		}
+		return (10-(sum+4)%10)%10 == d[7]
+	},
+	"BE": func(n string) bool {
+		v, _ := strconv.Atoi(n[:8])
+		check, _ := strconv.Atoi(n[8:])
+		return 97-v%97 == check
+	},
+	"DE": mod11x10,
+	"HR": mod11x10,
+	"DK": func(n string) bool {
+		return weightedSum(digitValues(n), []int{2, 7, 6, 5, 4, 3, 2, 1})%11 == 0
+	},
+	"EE": func(n string) bool {
+		d := digitValues(n)
+		return (10-weightedSum(d[:8], []int{3, 7, 1, 3, 7, 1, 3, 7})%10)%10 == d[8]
+	},
+	"FI": func(n string) bool {
+		d := digitValues(n)
+		r := weightedSum(d[:7], []int{7, 9, 10, 5, 8, 4, 2}) % 11
+		return r != 1 && (11-r)%11 == d[7]
+	},
+	"FR": func(n string) bool {
+		key, err := strconv.Atoi(n[:2])
+		if err != nil {
+			// Keys with letters have no published check, but the SIREN has a Luhn digit.
+			return luhnValid(n[2:])
+		}
+		siren, _ := strconv.Atoi(n[2:])
+		return key == (12+3*(siren%97))%97
+	},
+	"IT": luhnValid,
+	"LU": func(n string) bool {
+		v, _ := strconv.Atoi(n[:6])
+		check, _ := strconv.Atoi(n[6:])
+		return v%89 == check
+	},
+	"NL": func(n string) bool {
+		// Numbers issued since 2020 use mod 97 on the whole number, older ones the 11-proof.
+		return mod97("NL"+n) == 1 || validateBSN(n[:9])
+	},
+	"PL": func(n string) bool {
+		d := digitValues(n)
+		return weightedSum(d[:9], []int{6, 5, 7, 2, 3, 4, 5, 6, 7})%11 == d[9]
+	},
+	"PT": func(n string) bool {
+		d := digitValues(n)
+		check := 11 - weightedSum(d[:8], []int{9, 8, 7, 6, 5, 4, 3, 2})%11
+		if check >= 10 {
+			check = 0
+		}
+		return check == d[8]
+	},
+	"SE": func(n string) bool { return luhnValid(n[:10]) },
+	"SI": func(n string) bool {
+		d := digitValues(n)
+		check := 11 - weightedSum(d[:7], []int{8, 7, 6, 5, 4, 3, 2})%11
+		if check == 10 {
+			check = 0
+		}
+		return check != 11 && check == d[7]
+	},
+	"SK": func(n string) bool {
+		v, _ := strconv.Atoi(n)
+		return v%11 == 0
+	},
+	"ES": func(n string) bool {
+		// Individuals use their DNI or NIE number, legal entities a CIF.
+		if n[0] >= '0' && n[0] <= '9' || strings.IndexByte("XYZ", n[0]) >= 0 {
+			return validateDNI(n)
+		}
+		return validateCIF(n)
+	},
+	"RO": func(n string) bool {
+		d := digitValues(strings.Repeat("0", 10-len(n)) + n)
+		return weightedSum(d[:9], []int{7, 5, 3, 2, 1, 7, 5, 3, 2})*10%11%10 == d[9]
+	},
+	"HU": func(n string) bool {
+		return weightedSum(digitValues(n), []int{9, 7, 3, 1, 9, 7, 3, 1})%10 == 0
+	},
+	"MT": func(n string) bool {
+		return n[0] != '0' && weightedSum(digitValues(n), []int{3, 4, 6, 7, 8, 9, 10, 1})%37 == 0
+	},
+	"LV": func(n string) bool {
+		d := digitValues(n)
+		if d[0] > 3 {
+			// Legal entities.
+			return weightedSum(d, []int{9, 1, 4, 8, 3, 10, 2, 5, 7, 6, 1})%11 == 3
+		}
+		// Individuals use their personal code: a DDMMYY birth date, a century digit and a
+		// check digit. Codes issued since 2017 start with 32 and have neither.
+		day, month := d[0]*10+d[1], d[2]*10+d[3]
+		if day < 1 || day > 31 || month < 1 || month > 12 || d[6] > 2 {
+			return false
+		}
+		return (1+weightedSum(d, []int{10, 5, 8, 4, 2, 1, 6, 3, 7, 9}))%11%10 == d[10]
+	},
+	"LT": func(n string) bool {
+		// The digit before the check digit is 1 for VAT numbers.
+		d := digitValues(n)
+		if d[len(d)-2] != 1 {
+			return false
+		}
+		check := 0
+		for i, x := range d[:len(d)-1] {
+			check += (1 + i%9) * x
+		}
+		if check %= 11; check == 10 {
+			check = 0
+			for i, x := range d[:len(d)-1] {
+				check += (1 + (i+2)%9) * x
+			}
+			check %= 11
+		}
+		return check%10 == d[len(d)-1]
+	},
+	"CZ": func(n string) bool {
+		d := digitValues(n)
+		switch {
+		case len(d) == 8:
+			// Legal entities.
+			check := (11 - weightedSum(d, []int{8, 7, 6, 5, 4, 3, 2})%11) % 11
+			if check == 0 {
+				check = 1
+			}
+			return d[0] != 9 && check%10 == d[7]
+		case len(d) == 9 && d[0] == 6:
+			// Individuals without a birth number.
+			check := weightedSum(d[1:], []int{8, 7, 6, 5, 4, 3, 2}) % 11
+			return 9-(11-check)%11%10 == d[8]
+		}
+		// Individuals use their birth number: YYMMDD, with 50 added to the month for
+		// women and 20 more for numbers issued since 2004, then three or four digits. The
+		// ten digit numbers issued since 1954 are multiples of 11.
+		month, day := d[2]*10+d[3], d[4]*10+d[5]
+		if month > 50 {
+			month -= 50
+		}
+		if month > 20 {
+			month -= 20
+		}
+		if month < 1 || month > 12 || day < 1 || day > 31 {
+			return false
+		}
+		if len(d) == 9 {
+			return true
+		}
+		v, _ := strconv.Atoi(n)
+		first, _ := strconv.Atoi(n[:9])
+		return v%11 == 0 || first%11 == 10 && d[9] == 0
+	},
+	"BG": func(n string) bool {
+		d := digitValues(n)
+		if len(d) == 9 {
+			// Legal entities.
+			check := 0
+			for i, x := range d[:8] {
+				check += (i + 1) * x
+			}
+			if check %= 11; check == 10 {
+				check = 0
+				for i, x := range d[:8] {
+					check += (i + 3) * x
+				}
+				check %= 11
+			}
+			return check%10 == d[8]
+		}
+		// Individuals use their personal number, foreigners their personal number of a
+		// foreigner, and others a number of their own.
+		if weightedSum(d[:9], []int{2, 4, 8, 5, 10, 9, 7, 3, 6})%11%10 == d[9] ||
+			weightedSum(d[:9], []int{21, 19, 17, 13, 11, 9, 7, 3, 1})%10 == d[9] {
+			return true
+		}
+		check := 11 - weightedSum(d[:9], []int{4, 3, 2, 7, 6, 5, 4, 3, 2})%11
+		return check != 10 && check%11 == d[9]
+	},
+	"CY": func(n string) bool {
+		// Odd positions are weighted as in an Italian fiscal code.
+		if n[:2] == "12" {
+			return false
+		}
+		sum := 0
+		for i, x := range digitValues(n[:8]) {
+			if i%2 == 0 {
+				x = fiscalCodeOdd[x]
+			}
+			sum += x
+		}
+		return n[8] == byte('A'+sum%26)
+	},
+	"EL": func(n string) bool {
+		d := digitValues(n)
+		sum := 0
+		for _, x := range d[:8] {
+			sum = 2*sum + x
+		}
+		return 2*sum%11%10 == d[8]
+	},
+	"IE": func(n string) bool {
+		if n[1] < '0' || n[1] > '9' {
+			// Old numbers: a digit, a letter or symbol, five digits and the check letter.
+			return n[7] == irishVATCheck("0"+n[2:7]+n[:1], "")
+		}
+		return n[7] == irishVATCheck(n[:7], n[8:])
+	},
+}
+
+// validateCIF checks the control character of a Spanish legal entity's tax code: a
+// digit, or a letter standing for it for some kinds of entity.
+func validateCIF(n string) bool {
+	sum := 0
+	for i, x := range digitValues(n[1:8]) {
+		if i%2 == 0 {
+			x = 2*x/10 + 2*x%10
+		}
+		sum += x
+	}
+	check := (10 - sum%10) % 10
+	return n[8] == byte('0'+check) || n[8] == "JABCDEFGHI"[check]
+}
+
+const irishVATLetters = "WABCDEFGHIJKLMNOPQRSTUV"
+
+// irishVATCheck returns the check letter of an Irish VAT number's seven digits and
+// optional second letter.
+func irishVATCheck(digits, letter string) byte {
+	sum := weightedSum(digitValues(digits), []int{8, 7, 6, 5, 4, 3, 2})
+	if letter != "" {
+		sum += 9 * strings.IndexByte(irishVATLetters, letter[0])
+	}
+	return irishVATLetters[sum%23]
+}
+
+// validateEUVAT checks the format of a VAT number for its member state and its check
+// digits.
+func validateEUVAT(match string) bool {
+	vat := strings.ReplaceAll(match, " ", "")
+	if len(vat) < 4 {
+		return false
+	}
+	country, number := vat[:2], vat[2:]
+	format, ok := vatFormats[country]
+	if !ok || !format.MatchString(number) {
+		return false
+	}
+	return vatChecks[country](number)
+}
+
+// validateSteuerID checks a German tax ID: in its first ten digits one digit appears
+// two or three times and the others at most once, and the last digit is an ISO 7064
+// mod 11,10 check digit.
+func validateSteuerID(match string) bool {
+	id := onlyDigits(match)
+	if len(id) != 11 || !numberLike(match) {
+		return false
+	}
+	counts := make(map[rune]int)
+	for _, r := range id[:10] {
+		counts[r]++
+	}
+	repeated := 0
+	for _, c := range counts {
+		if c > 3 {
+			return false
+		}
+		if c > 1 {
+			repeated++
+		}
+	}
+	return repeated == 1 && mod11x10(id)
+}
+
+// mod11x10 checks the ISO 7064 mod 11,10 check digit at the end of n.
+func mod11x10(n string) bool {
+	d := digitValues(n)
+	product := 10
+	for _, x := range d[:len(d)-1] {
+		sum := (x + product) % 10
+		if sum == 0 {
+			sum = 10
+		}
+		product = (2 * sum) % 11
+	}
+	check := 11 - product
+	if check == 10 {
+		check = 0
+	}
+	return check == d[len(d)-1]
+}
+
+// validateNIR checks the key of a French social security number: 97 minus the first
+// thirteen digits modulo 97, with Corsica's 2A and 2B read as 19 and 18.
+func validateNIR(match string) bool {
+	nir := strings.ReplaceAll(match, " ", "")
+	if len(nir) != 15 {
+		return false
+	}
+	nir = strings.NewReplacer("2A", "19", "2B", "18").Replace(nir[:7]) + nir[7:]
+	if len(onlyDigits(nir)) != 15 {
+		return false
+	}
+	v, _ := strconv.ParseInt(nir[:13], 10, 64)
+	key, _ := strconv.Atoi(nir[13:])
+	return 97-v%97 == int64(key)
+}
+
+const dniLetters = "TRWAGMYFPDXBNJZSQVHLCKE"
+
+// validateDNI checks the control letter of a Spanish DNI or NIE. A NIE's leading X, Y
+// or Z counts as 0, 1 or 2.
+func validateDNI(match string) bool {
+	id := strings.ReplaceAll(match, "-", "")
+	if len(id) != 9 {
+		return false
+	}
+	id = strings.NewReplacer("X", "0", "Y", "1", "Z", "2").Replace(id[:1]) + id[1:]
+	if len(onlyDigits(id[:8])) != 8 {
+		return false
+	}
+	v, _ := strconv.Atoi(id[:8])
+	return dniLetters[v%23] == id[8]
+}
+
+// Values of the characters of a codice fiscale in odd positions; even positions use
+// the digit or the letter's index in the alphabet.
+var fiscalCodeOdd = [36]int{
+	1, 0, 5, 7, 9, 13, 15, 17, 19, 21, // 0-9
+	1, 0, 5, 7, 9, 13, 15, 17, 19, 21, 2, 4, 18, 20, 11, 3, 6, 8, 12, 14, 16, 10, 22, 25, 24, 23, // A-Z
+}
+
+// validateCodiceFiscale checks the control letter of an Italian fiscal code.
+func validateCodiceFiscale(match string) bool {
+	if len(match) != 16 || strings.Trim(match, "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 15; i++ {
+		c := match[i]
+		index, even := int(c-'A')+10, int(c-'A')
+		if c >= '0' && c <= '9' {
+			index, even = int(c-'0'), int(c-'0')
+		}
+		if i%2 == 0 {
+			sum += fiscalCodeOdd[index]
+		} else {
+			sum += even
+		}
+	}
+	return match[15] == byte('A'+sum%26)
+}
+
+// validateBSN applies the 11-proof to a Dutch citizen service number: the digits
+// weighted 9 down to 2, minus the last digit, are a multiple of 11.
+func validateBSN(match string) bool {
+	d := digitValues(onlyDigits(match))
+	if len(d) != 9 || !numberLike(match) {
+		return false
+	}
+	sum := weightedSum(d[:8], []int{9, 8, 7, 6, 5, 4, 3, 2}) - d[8]
+	return sum != 0 && sum%11 == 0
+}
+
+// luhnValid applies the Luhn check to a string of digits of any length.
+func luhnValid(digits string) bool {
+	sum := 0
+	for i, x := range reverseInts(digitValues(digits)) {
+		if i%2 == 1 {
+			x *= 2
+			if x > 9 {
+				x -= 9
+			}
+		}
+		sum += x
+	}
+	return sum%10 == 0
+}
+
+func digitValues(s string) []int {
+	d := make([]int, 0, len(s))
+	for _, r := range s {
+		d = append(d, int(r-'0'))
+	}
+	return d
+}
+
+func weightedSum(d, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += d[i] * w
+	}
+	return sum
+}
+
+func reverseInts(d []int) []int {
+	out := make([]int, len(d))
+	for i, x := range d {
+		out[len(d)-1-i] = x
+	}
+	return out
+}
@@ -0,0 +1,169 @@
+package detector
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// enGBPatterns is the United Kingdom locale pack. Numbers without a distinctive format,
+// such as passport and account numbers, start below the default threshold and are only
+// reported with one of their keywords nearby.
+var enGBPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:          "UK_NINO",
+		Pattern:       regexp.MustCompile(`\b[A-Z]{2} ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`),
+		Validator:     validateNINO,
+		ValidatorName: "uk_nino",
+		Confidence:    0.90,
+		Keywords:      []string{"national insurance", "nino", "ni number", "ni no"},
+	},
+	{
+		Name:          "UK_NHS_NUMBER",
+		Pattern:       regexp.MustCompile(`\b\d{3}[ -]?\d{3}[ -]?\d{4}\b`),
+		Validator:     validateNHSNumber,
+		ValidatorName: "uk_nhs",
+		Confidence:    0.65,
+		Keywords:      []string{"nhs", "patient", "health"},
+	},
+	{
+		Name:          "UK_POSTCODE",
+		Pattern:       regexp.MustCompile(`\b(?:[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}|GIR ?0AA)\b`),
+		Validator:     validateUKPostcode,
+		ValidatorName: "uk_postcode",
+		Confidence:    0.85,
+		Keywords:      []string{"postcode", "post code", "address"},
+	},
+	{
+		Name:       "UK_SORT_CODE",
+		Pattern:    regexp.MustCompile(`\b\d{2}-\d{2}-\d{2}\b`),
+		Confidence: 0.50,
+		Keywords:   []string{"sort code", "sortcode", "bank"},
+	},
+	{
+		Name:       "UK_BANK_ACCOUNT",
+		Pattern:    regexp.MustCompile(`\b\d{8}\b`),
+		Confidence: 0.50,
+		Keywords:   []string{"account", "acc no", "a/c"},
+	},
+	{
+		Name:       "UK_PASSPORT",
+		Pattern:    regexp.MustCompile(`\b\d{9}\b`),
+		Confidence: 0.50,
+		Keywords:   []string{"passport"},
+	},
+	{
+		Name:          "UK_DRIVING_LICENCE",
+		Pattern:       regexp.MustCompile(`\b[A-Z9]{5}\d{6}[A-Z9]{2}\d[A-Z]{2}\b`),
+		Validator:     validateUKDrivingLicence,
+		ValidatorName: "uk_driving_licence",
+		Confidence:    0.90,
+		Keywords:      []string{"driving licence", "driver", "licence", "dvla"},
+	},
+}
+
+// Formats of the validated numbers with spaces removed, checked first because custom
+// patterns can pass the validators anything.
+var (
+	ninoFormat           = regexp.MustCompile(`^[A-Z]{2}\d{6}[A-D]$`)
+	ukPostcodeFormat     = regexp.MustCompile(`^(?:[A-Z]{1,2}\d[A-Z\d]?\d[A-Z]{2}|GIR0AA)$`)
+	drivingLicenceFormat = regexp.MustCompile(`^[A-Z9]{5}\d{6}[A-Z9]{2}\d[A-Z]{2}$`)
+)
+
+// validateNINO applies the National Insurance number prefix rules: D, F, I, Q, U and V
+// are never used, O is not used as the first letter, and some pairs are not allocated.
+func validateNINO(match string) bool {
+	nino := strings.ReplaceAll(match, " ", "")
+	if !ninoFormat.MatchString(nino) {
+		return false
+	}
+	first, second := nino[0], nino[1]
+	if strings.IndexByte("DFIQUV", first) >= 0 || strings.IndexByte("DFIOQUV", second) >= 0 {
+		return false
+	}
+	switch nino[:2] {
+	case "BG", "GB", "KN", "NK", "NT", "TN", "ZZ":
+		return false
+	}
+	return true
+}
+
+// validateNHSNumber checks the modulus 11 check digit of a ten digit NHS number.
+func validateNHSNumber(match string) bool {
+	digits := onlyDigits(match)
+	if len(digits) != 10 || !numberLike(match) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(digits[i]-'0') * (10 - i)
+	}
+	check := 11 - sum%11
+	if check == 11 {
+		check = 0
+	}
+	return check != 10 && check == int(digits[9]-'0')
+}
+
+// validateUKPostcode rejects letters that are never used in their position.
+func validateUKPostcode(match string) bool {
+	postcode := strings.ReplaceAll(match, " ", "")
+	if !ukPostcodeFormat.MatchString(postcode) {
+		return false
+	}
+	if postcode == "GIR0AA" {
+		return true
+	}
+	outward, inward := postcode[:len(postcode)-3], postcode[len(postcode)-3:]
+	if strings.IndexByte("QVX", outward[0]) >= 0 {
+		return false
+	}
+	if len(outward) > 1 && outward[1] >= 'A' && strings.IndexByte("IJZ", outward[1]) >= 0 {
+		return false
+	}
+	return strings.IndexAny(inward[1:], "CIKMOV") < 0
+}
+
+// validateUKPhone checks the length and leading digit of the national number.
+func validateUKPhone(match string) bool {
+	if !numberLike(match) {
+		return false
+	}
+	digits := onlyDigits(match)
+	if strings.HasPrefix(strings.TrimSpace(match), "+44") {
+		digits = strings.TrimPrefix(digits, "44")
+	}
+	digits = strings.TrimPrefix(digits, "0")
+	return (len(digits) == 9 || len(digits) == 10) && strings.IndexByte("123578", digits[0]) >= 0
+}
+
+// validateUKDrivingLicence checks the date of birth encoded in a DVLA licence number:
+// the month is offset by 50 for women.
+func validateUKDrivingLicence(match string) bool {
+	if !drivingLicenceFormat.MatchString(match) {
+		return false
+	}
+	surname := strings.TrimRight(match[:5], "9")
+	if surname == "" || strings.Contains(surname, "9") {
+		return false
+	}
+	month, _ := strconv.Atoi(match[6:8])
+	if month > 50 {
+		month -= 50
+	}
+	day, _ := strconv.Atoi(match[8:10])
+	return month >= 1 && month <= 12 && day >= 1 && day <= 31 && match[11] != '9'
+}
+
+// onlyDigits returns the ASCII digits in s.
+func onlyDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
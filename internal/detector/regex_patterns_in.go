@@ -0,0 +1,144 @@
+package detector
+
+import (
+	"regexp"
+	"strings"
+)
+
+// enINPatterns is the India locale pack. Aadhaar numbers are masked to their last four
+// digits by the redactor's mask mode, as UIDAI requires for displayed numbers.
+var enINPatterns = []RegexPattern{
+	emailPattern,
+	creditCardPattern,
+	ipAddressPattern,
+	{
+		Name:          "IN_AADHAAR",
+		Pattern:       regexp.MustCompile(`\b[2-9]\d{3}[ -]?\d{4}[ -]?\d{4}\b`),
+		Validator:     validateAadhaar,
+		ValidatorName: "verhoeff",
+		Confidence:    0.90,
+		Keywords:      []string{"aadhaar", "aadhar", "uidai", "uid"},
+	},
+	{
+		Name: "IN_PAN",
+		// The fourth letter is the holder type, such as P for a person or C for a company.
+		Pattern:    regexp.MustCompile(`\b[A-Z]{3}[ABCFGHJLPT][A-Z]\d{4}[A-Z]\b`),
+		Confidence: 0.85,
+		Keywords:   []string{"pan", "income tax", "permanent account"},
+	},
+	{
+		Name:          "IN_GSTIN",
+		Pattern:       regexp.MustCompile(`\b\d{2}[A-Z]{3}[ABCFGHJLPT][A-Z]\d{4}[A-Z][1-9A-Z]Z[0-9A-Z]\b`),
+		Validator:     validateGSTIN,
+		ValidatorName: "gstin",
+		Confidence:    0.95,
+		Keywords:      []string{"gst", "gstin"},
+	},
+	{
+		Name:       "IN_IFSC",
+		Pattern:    regexp.MustCompile(`\b[A-Z]{4}0[A-Z0-9]{6}\b`),
+		Confidence: 0.80,
+		Keywords:   []string{"ifsc", "bank", "branch"},
+	},
+	{
+		Name:          "IN_UPI_ID",
+		Pattern:       regexp.MustCompile(`\b[A-Za-z0-9._\-]{2,256}@[A-Za-z]{2,64}\b`),
+		Validator:     validateUPIHandle,
+		ValidatorName: "upi",
+		Confidence:    0.90,
+		Keywords:      []string{"upi", "vpa", "gpay", "phonepe", "paytm"},
+	},
+}
+
+// Verhoeff checksum tables: multiplication in the dihedral group D5, and the
+// permutation applied at each position.
+var (
+	verhoeffD = [10][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffP = [8][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 6, 8, 7, 0},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+)
+
+// validateAadhaar checks the Verhoeff check digit of an Aadhaar number.
+func validateAadhaar(match string) bool {
+	digits := onlyDigits(match)
+	if len(digits) != 12 || !numberLike(match) {
+		return false
+	}
+	c := 0
+	for i, x := range reverseInts(digitValues(digits)) {
+		c = verhoeffD[c][verhoeffP[i%8][x]]
+	}
+	return c == 0
+}
+
+const gstinAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// validateGSTIN checks the state code and the base 36 check character of a GSTIN.
+func validateGSTIN(match string) bool {
+	if len(match) != 15 {
+		return false
+	}
+	if match[:2] == "00" || (match[:2] > "38" && match[:2] != "97" && match[:2] != "99") {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 14; i++ {
+		value := strings.IndexByte(gstinAlphabet, match[i])
+		if value < 0 {
+			return false
+		}
+		product := value * (i%2 + 1)
+		sum += product/36 + product%36
+	}
+	return match[14] == gstinAlphabet[(36-sum%36)%36]
+}
+
+// validateIndianMobile checks that the number has ten digits after any +91 or 0 prefix.
+func validateIndianMobile(match string) bool {
+	if !numberLike(match) {
+		return false
+	}
+	digits := onlyDigits(match)
+	if strings.HasPrefix(match, "+91") {
+		digits = digits[2:]
+	}
+	return len(strings.TrimPrefix(digits, "0")) == 10
+}
+
+// upiHandles are the payment service provider handles of common UPI apps. A VPA has
+// no dot after the @, so email addresses are not mistaken for one.
+var upiHandles = []string{
+	"upi", "ybl", "ibl", "axl", "apl", "yapl", "paytm", "ptyes", "ptaxis", "pthdfc", "ptsbi",
+	"okhdfcbank", "okicici", "oksbi", "okaxis", "axisbank", "hdfcbank", "icici", "sbi", "kotak",
+	"waicici", "wahdfcbank", "wasbi", "waaxis", "freecharge", "airtel", "jupiteraxis", "fam", "slc",
+}
+
+func validateUPIHandle(match string) bool {
+	_, handle, _ := strings.Cut(match, "@")
+	handle = strings.ToLower(handle)
+	for _, h := range upiHandles {
+		if h == handle {
+			return true
+		}
+	}
+	return false
+}
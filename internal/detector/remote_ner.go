@@ -0,0 +1,454 @@
+package detector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// ErrCircuitOpen is returned by RemoteNERDetector while the sidecar is considered down
+// and there is no fallback detector.
+var ErrCircuitOpen = errors.New("remote NER circuit open")
+
+// RemoteNERMethod is the gRPC method called on a NER sidecar with a grpc:// or grpcs://
+// URL. Its request and response are google.protobuf.Struct messages holding the same
+// fields as RemoteNERRequest and RemoteNERResponse.
+const RemoteNERMethod = "/pii.ner.v1.NER/Recognize"
+
+// RemoteNERRequest is the body POSTed to the NER sidecar.
+type RemoteNERRequest struct {
+	Documents []RemoteNERDocument `json:"documents"`
+}
+
+type RemoteNERDocument struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	// Language is the ISO 639-1 code of the request's primary locale, such as "de", so
+	// the sidecar can pick a model for it.
+	Language string `json:"language,omitempty"`
+}
+
+// RemoteNERResponse is the sidecar's reply. It must contain one result per document.
+// Offsets count Unicode code points, as Python string indices do, unless OffsetUnit
+// is "bytes", the API's own name for UTF-8 byte offsets. "byte" is accepted too.
+type RemoteNERResponse struct {
+	Documents  []RemoteNERResult `json:"documents"`
+	OffsetUnit string            `json:"offset_unit,omitempty"`
+}
+
+type RemoteNERResult struct {
+	ID       string            `json:"id"`
+	Entities []RemoteNEREntity `json:"entities"`
+}
+
+// RemoteNEREntity is one span found by the sidecar, with a score in (0, 1].
+type RemoteNEREntity struct {
+	Start int     `json:"start"`
+	End   int     `json:"end"`
+	Label string  `json:"label"`
+	Score float64 `json:"score"`
+}
+
+// RemoteNERConfig configures a RemoteNERDetector.
+type RemoteNERConfig struct {
+	// URL is an http:// or https:// URL to POST to, or a grpc:// (plaintext) or
+	// grpcs:// (TLS) host and port to call RemoteNERMethod on.
+	URL    string
+	APIKey string // Sent as a bearer token when set
+	// Timeout bounds each call to the sidecar.
+	Timeout time.Duration
+	// Concurrent Detect calls are sent together, up to BatchSize documents per call,
+	// waiting at most BatchWindow for a batch to fill.
+	BatchSize   int
+	BatchWindow time.Duration
+	// After FailureThreshold consecutive failed calls the circuit opens for Cooldown,
+	// during which requests go straight to Fallback.
+	FailureThreshold int
+	Cooldown         time.Duration
+	// Fallback handles requests while the sidecar fails. Nil means return the error.
+	Fallback Detector
+	Client   *http.Client // Used for HTTP sidecars
+}
+
+// RemoteNERDetector sends text to an external NER service, such as a spaCy or
+// transformer model, over HTTP or gRPC.
+type RemoteNERDetector struct {
+	cfg    RemoteNERConfig
+	client *http.Client
+	// conn is set for gRPC sidecars. connErr is returned by every call when the
+	// connection could not be set up.
+	conn    *grpc.ClientConn
+	connErr error
+	queue   chan *remoteNERCall
+	stop    chan struct{}
+	once    sync.Once
+	breaker circuitBreaker
+}
+
+type remoteNERCall struct {
+	text     string
+	language string
+	done     chan remoteNERReply
+}
+
+type remoteNERReply struct {
+	entities  []RemoteNEREntity
+	byteUnits bool
+	err       error
+}
+
+func NewRemoteNERDetector(cfg RemoteNERConfig) *RemoteNERDetector {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 16
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	d := &RemoteNERDetector{
+		cfg:     cfg,
+		client:  client,
+		queue:   make(chan *remoteNERCall),
+		stop:    make(chan struct{}),
+		breaker: circuitBreaker{threshold: cfg.FailureThreshold, cooldown: cfg.Cooldown},
+	}
+	if u, err := url.Parse(cfg.URL); err == nil && (u.Scheme == "grpc" || u.Scheme == "grpcs") {
+		creds := insecure.NewCredentials()
+		if u.Scheme == "grpcs" {
+			creds = credentials.NewTLS(nil)
+		}
+		// The connection is made on the first call and re-established as needed.
+		d.conn, err = grpc.NewClient(u.Host, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			d.connErr = fmt.Errorf("invalid remote NER gRPC target: %w", err)
+		}
+	}
+	go d.batch()
+	return d
+}
+
+func (d *RemoteNERDetector) Name() string {
+	return "remote_ner"
+}
+
+// Close stops the batching goroutine and closes the gRPC connection. Detect must not
+// be called afterwards.
+func (d *RemoteNERDetector) Close() {
+	d.once.Do(func() {
+		close(d.stop)
+		if d.conn != nil {
+			d.conn.Close()
+		}
+	})
+}
+
+func (d *RemoteNERDetector) Detect(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+	if !d.breaker.allow() {
+		return d.fallback(ctx, req, ErrCircuitOpen)
+	}
+
+	call := &remoteNERCall{text: req.Text, done: make(chan remoteNERReply, 1)}
+	if req.Locale != "" && !strings.EqualFold(req.Locale, AutoLocale) {
+		call.language = localeLanguage(req.Locale)
+	}
+	select {
+	case d.queue <- call:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case reply := <-call.done:
+		if reply.err != nil {
+			return d.fallback(ctx, req, reply.err)
+		}
+		return d.detections(req, reply.entities, reply.byteUnits), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (d *RemoteNERDetector) fallback(ctx context.Context, req model.DetectionRequest, err error) ([]model.Detection, error) {
+	if d.cfg.Fallback == nil {
+		return nil, err
+	}
+	log.Debug().Err(err).Msg("Remote NER unavailable, using fallback detector")
+	return d.cfg.Fallback.Detect(ctx, req)
+}
+
+func (d *RemoteNERDetector) detections(req model.DetectionRequest, entities []RemoteNEREntity, byteUnits bool) []model.Detection {
+	var runeOffsets []int
+	var detections []model.Detection
+	for _, e := range entities {
+		start, end := e.Start, e.End
+		if !byteUnits {
+			if runeOffsets == nil {
+				runeOffsets = runeToByteOffsets(req.Text)
+			}
+			if start < 0 || end >= len(runeOffsets) {
+				continue
+			}
+			start, end = runeOffsets[start], runeOffsets[end]
+		}
+		if start < 0 || end > len(req.Text) || start >= end ||
+			!utf8.RuneStart(req.Text[start]) || (end < len(req.Text) && !utf8.RuneStart(req.Text[end])) {
+			log.Warn().Int("start", e.Start).Int("end", e.End).Msg("Remote NER returned an invalid span, skipping it")
+			continue
+		}
+		entityType := mapRemoteLabel(e.Label)
+		if entityType == "" {
+			continue
+		}
+		confidence := e.Score
+		if confidence <= 0 || confidence > 1 {
+			confidence = nerConfidence
+		}
+		det := model.Detection{
+			EntityType:      entityType,
+			Text:            req.Text[start:end],
+			Start:           start,
+			End:             end,
+			Confidence:      confidence,
+			DetectionMethod: d.Name(),
+		}
+		if req.Explain {
+			det.Explanation = &model.Explanation{Source: d.Name(), Pattern: "remote/" + e.Label, BaseConfidence: confidence}
+		}
+		detections = append(detections, det)
+	}
+	return detections
+}
+
+// batch collects queued calls into batches and sends each one on its own goroutine.
+func (d *RemoteNERDetector) batch() {
+	for {
+		var calls []*remoteNERCall
+		select {
+		case call := <-d.queue:
+			calls = append(calls, call)
+		case <-d.stop:
+			return
+		}
+
+		timer := time.NewTimer(d.cfg.BatchWindow)
+	fill:
+		for len(calls) < d.cfg.BatchSize {
+			select {
+			case call := <-d.queue:
+				calls = append(calls, call)
+			case <-timer.C:
+				break fill
+			case <-d.stop:
+				timer.Stop()
+				return
+			}
+		}
+		timer.Stop()
+		go d.send(calls)
+	}
+}
+
+func (d *RemoteNERDetector) send(calls []*remoteNERCall) {
+	res, err := d.call(calls)
+	if err != nil {
+		d.breaker.failure()
+	} else {
+		d.breaker.success()
+	}
+	for i, call := range calls {
+		if err != nil {
+			call.done <- remoteNERReply{err: err}
+			continue
+		}
+		call.done <- remoteNERReply{entities: res.results[strconv.Itoa(i)], byteUnits: res.byteUnits}
+	}
+}
+
+type remoteNERBatch struct {
+	results   map[string][]RemoteNEREntity
+	byteUnits bool
+}
+
+func (d *RemoteNERDetector) call(calls []*remoteNERCall) (remoteNERBatch, error) {
+	var batch remoteNERBatch
+	body := RemoteNERRequest{Documents: make([]RemoteNERDocument, len(calls))}
+	for i, call := range calls {
+		body.Documents[i] = RemoteNERDocument{ID: strconv.Itoa(i), Text: call.text, Language: call.language}
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return batch, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.cfg.Timeout)
+	defer cancel()
+	var out RemoteNERResponse
+	if d.conn != nil || d.connErr != nil {
+		err = d.invoke(ctx, payload, &out)
+	} else {
+		err = d.post(ctx, payload, &out)
+	}
+	if err != nil {
+		return batch, err
+	}
+	batch.results = make(map[string][]RemoteNEREntity, len(out.Documents))
+	for _, doc := range out.Documents {
+		batch.results[doc.ID] = doc.Entities
+	}
+	if len(batch.results) != len(calls) {
+		return batch, fmt.Errorf("remote NER returned %d results for %d documents", len(batch.results), len(calls))
+	}
+	batch.byteUnits = out.OffsetUnit == string(model.ByteOffsets) || out.OffsetUnit == "byte"
+	return batch, nil
+}
+
+// post sends the JSON request body to an HTTP sidecar.
+func (d *RemoteNERDetector) post(ctx context.Context, payload []byte, out *RemoteNERResponse) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, d.cfg.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if d.cfg.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+d.cfg.APIKey)
+	}
+
+	resp, err := d.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("remote NER request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		return fmt.Errorf("remote NER returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode remote NER response: %w", err)
+	}
+	return nil
+}
+
+// invoke calls RemoteNERMethod on a gRPC sidecar, carrying the JSON request and
+// response bodies as google.protobuf.Struct messages.
+func (d *RemoteNERDetector) invoke(ctx context.Context, payload []byte, out *RemoteNERResponse) error {
+	if d.connErr != nil {
+		return d.connErr
+	}
+	in := &structpb.Struct{}
+	if err := protojson.Unmarshal(payload, in); err != nil {
+		return err
+	}
+	if d.cfg.APIKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+d.cfg.APIKey)
+	}
+	res := &structpb.Struct{}
+	if err := d.conn.Invoke(ctx, RemoteNERMethod, in, res); err != nil {
+		return fmt.Errorf("remote NER request failed: %w", err)
+	}
+	body, err := protojson.Marshal(res)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode remote NER response: %w", err)
+	}
+	return nil
+}
+
+// mapRemoteLabel maps common NER label sets (spaCy, CoNLL) to entity types. Labels
+// that are already entity types of this API pass through unchanged.
+func mapRemoteLabel(label string) string {
+	switch label = strings.ToUpper(label); label {
+	case "PER", "PERSON":
+		return "PERSON"
+	case "GPE", "LOC", "LOCATION", "FAC":
+		return "LOCATION"
+	case "ORG", "ORGANIZATION":
+		return "ORGANIZATION"
+	case "DATE":
+		return "DATE"
+	case "MISC", "NORP", "CARDINAL", "ORDINAL", "QUANTITY", "PERCENT", "MONEY", "TIME",
+		"PRODUCT", "EVENT", "WORK_OF_ART", "LAW", "LANGUAGE":
+		return ""
+	default:
+		return label
+	}
+}
+
+// runeToByteOffsets returns the byte offset of every code point index in text,
+// including the end of the text.
+func runeToByteOffsets(text string) []int {
+	offsets := make([]int, 0, len(text)+1)
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	return append(offsets, len(text))
+}
+
+// circuitBreaker opens after threshold consecutive failures. Once cooldown has passed
+// it lets a single trial call through; its outcome closes or reopens the circuit.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if time.Now().Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures, b.trial = 0, false
+}
+
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
@@ -0,0 +1,219 @@
+package detector
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	unitWords = map[string]int{
+		"zero": 0, "oh": 0, "one": 1, "two": 2, "three": 3, "four": 4,
+		"five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+	}
+	teenWords = map[string]int{
+		"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14,
+		"fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+	}
+	tensWords = map[string]int{
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+		"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+	}
+	repeatWords = map[string]int{"double": 2, "triple": 3}
+
+	// spokenSymbols are the words read out for the punctuation in addresses.
+	spokenSymbols = map[string]string{"at": "@", "dot": ".", "underscore": "_", "dash": "-", "hyphen": "-", "slash": "/"}
+)
+
+var (
+	spokenToken = regexp.MustCompile(`[A-Za-z]+|\d+|\S`)
+	spokenEmail = regexp.MustCompile(`(?i)\b[a-z0-9]+(?:\s+(?:dot|underscore|dash|hyphen)\s+[a-z0-9]+)*\s+at\s+[a-z0-9]+(?:\s+(?:dot|dash|hyphen)\s+[a-z0-9]+)*\s+dot\s+[a-z]{2,}\b`)
+	spokenURL   = regexp.MustCompile(`(?i)\b[a-z0-9]+(?:\s+dot\s+[a-z0-9]+)*\s+dot\s+(?:com|net|org|edu|gov|io|co|uk|us)\b(?:\s+slash\s+[a-z0-9]+)*`)
+)
+
+// transcriptPatterns are matched in addition to the locale's patterns in transcript
+// mode, where numbers are often read out without pauses.
+var transcriptPatterns = map[string][]RegexPattern{
+	"en-US": {
+		{
+			ID:      "transcript/SSN",
+			Name:    "SSN",
+			Pattern: regexp.MustCompile(`\b\d{9}\b`),
+			Validator: func(match string) bool {
+				return validateSSN(match[:3] + "-" + match[3:5] + "-" + match[5:])
+			},
+			ValidatorName: "ssn",
+			// Nine bare digits need a keyword to reach the default threshold.
+			Confidence: 0.50,
+			Keywords:   []string{"ssn", "social", "security"},
+		},
+	},
+}
+
+// parseSpokenForms rewrites English speech transcripts into the written forms the
+// patterns match: number words become digits, with pauses (commas, "dash") between
+// groups written as "-", and spelled out email addresses and URLs are joined, so
+// "one two three, forty five" becomes "123-45" and "john dot smith at gmail dot com"
+// becomes "john.smith@gmail.com".
+func parseSpokenForms(text string, src offsetMap) (string, offsetMap) {
+	text, src = parseSpokenNumbers(text, src)
+	text, src = joinSpoken(text, src, spokenEmail)
+	return joinSpoken(text, src, spokenURL)
+}
+
+type spokenWord struct {
+	text       string // Lowercased
+	start, end int
+}
+
+func parseSpokenNumbers(text string, src offsetMap) (string, offsetMap) {
+	var words []spokenWord
+	for _, m := range spokenToken.FindAllStringIndex(text, -1) {
+		words = append(words, spokenWord{text: strings.ToLower(text[m[0]:m[1]]), start: m[0], end: m[1]})
+	}
+
+	w := rewriter{src: src}
+	last := 0
+	for i := 0; i < len(words); {
+		end, ok := numberSequence(words, i)
+		if !ok {
+			i++
+			continue
+		}
+		w.copy(text, last, words[i].start)
+		writeNumbers(&w, words[i:end])
+		last, i = words[end-1].end, end
+	}
+	w.copy(text, last, len(text))
+	return w.result()
+}
+
+// numberSequence returns the end of the number sequence starting at words[i]. A
+// sequence needs at least two number words and may contain pauses between them.
+func numberSequence(words []spokenWord, i int) (int, bool) {
+	if !isNumberWord(words, i) {
+		return 0, false
+	}
+	end, count, spelled := i+1, 1, !isDigits(words[i].text)
+	for end < len(words) {
+		next := end
+		if isPause(words[next].text) {
+			next++
+		}
+		if next >= len(words) || !isNumberWord(words, next) {
+			break
+		}
+		count++
+		spelled = spelled || !isDigits(words[next].text)
+		end = next + 1
+	}
+	return end, count >= 2 && spelled
+}
+
+func isNumberWord(words []spokenWord, i int) bool {
+	word := words[i].text
+	if _, ok := repeatWords[word]; ok {
+		return i+1 < len(words) && isUnit(words[i+1].text)
+	}
+	if word == "hundred" {
+		return i > 0 && isUnit(words[i-1].text)
+	}
+	_, teen := teenWords[word]
+	_, tens := tensWords[word]
+	return isUnit(word) || teen || tens || isDigits(word)
+}
+
+func isUnit(word string) bool {
+	_, ok := unitWords[word]
+	return ok
+}
+
+func isPause(word string) bool {
+	return word == "," || word == "-" || word == "dash" || word == "hyphen"
+}
+
+func isDigits(word string) bool {
+	return word != "" && strings.Trim(word, "0123456789") == ""
+}
+
+// writeNumbers writes the digits of a number sequence, mapping each number to the
+// words it was read from.
+func writeNumbers(w *rewriter, words []spokenWord) {
+	for i := 0; i < len(words); {
+		word := words[i]
+		switch {
+		case isPause(word.text):
+			w.write("-", word.start, word.end)
+			i++
+		case isDigits(word.text):
+			w.write(word.text, word.start, word.end)
+			i++
+		case repeatWords[word.text] > 0:
+			digit := strconv.Itoa(unitWords[words[i+1].text])
+			w.write(strings.Repeat(digit, repeatWords[word.text]), word.start, words[i+1].end)
+			i += 2
+		case isUnit(word.text) && i+1 < len(words) && words[i+1].text == "hundred":
+			// "five hundred" is 500 and "five hundred twelve" is 512.
+			value, next := belowHundred(words, i+2)
+			w.write(strconv.Itoa(unitWords[word.text]*100+value), word.start, words[next-1].end)
+			i = next
+		case word.text == "hundred":
+			// Left over from "twenty five hundred", which is 2500.
+			w.write("00", word.start, word.end)
+			i++
+		default:
+			value, next := belowHundred(words, i)
+			w.write(strconv.Itoa(value), word.start, words[next-1].end)
+			i = next
+		}
+	}
+}
+
+// belowHundred reads a number under one hundred, such as "forty five" or "forty-five",
+// from words[i:]. It returns 0 and i when there is none.
+func belowHundred(words []spokenWord, i int) (int, int) {
+	if i >= len(words) {
+		return 0, i
+	}
+	word := words[i].text
+	if v, ok := teenWords[word]; ok {
+		return v, i + 1
+	}
+	if v, ok := unitWords[word]; ok {
+		return v, i + 1
+	}
+	v, ok := tensWords[word]
+	if !ok {
+		return 0, i
+	}
+	next := i + 1
+	// A hyphen only joins the words of a compound when it touches both.
+	if next+1 < len(words) && words[next].text == "-" && words[next].start == words[i].end && words[next+1].start == words[next].end {
+		next++
+	}
+	if next < len(words) && unitWords[words[next].text] > 0 {
+		return v + unitWords[words[next].text], next + 1
+	}
+	return v, i + 1
+}
+
+// joinSpoken replaces the symbol words in each match of pattern and drops the spaces
+// between them.
+func joinSpoken(text string, src offsetMap, pattern *regexp.Regexp) (string, offsetMap) {
+	w := rewriter{src: src}
+	last := 0
+	for _, m := range pattern.FindAllStringIndex(text, -1) {
+		w.copy(text, last, m[0])
+		for _, t := range spokenToken.FindAllStringIndex(text[m[0]:m[1]], -1) {
+			start, end := m[0]+t[0], m[0]+t[1]
+			if symbol, ok := spokenSymbols[strings.ToLower(text[start:end])]; ok {
+				w.write(symbol, start, end)
+			} else {
+				w.copy(text, start, end)
+			}
+		}
+		last = m[1]
+	}
+	w.copy(text, last, len(text))
+	return w.result()
+}
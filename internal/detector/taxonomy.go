@@ -0,0 +1,77 @@
+package detector
+
+// entityParents places entity types in a taxonomy, so filters and thresholds can name a
+// category instead of listing every type in it. Types without an entry are top-level.
+var entityParents = map[string]string{
+	"PHONE_US":     "PHONE",
+	"PHONE":        "CONTACT",
+	"EMAIL":        "CONTACT",
+	"SSN":          "GOV_ID",
+	"CREDIT_CARD":  "FINANCIAL",
+	"IP_ADDRESS":   "NETWORK",
+	"PERSON":       "PERSONAL",
+	"LOCATION":     "PERSONAL",
+	"DATE":         "PERSONAL",
+	"ORGANIZATION": "ORGANIZATIONAL",
+
+	"PHONE_UK":           "PHONE",
+	"UK_NINO":            "GOV_ID",
+	"UK_NHS_NUMBER":      "GOV_ID",
+	"UK_PASSPORT":        "GOV_ID",
+	"UK_DRIVING_LICENCE": "GOV_ID",
+	"UK_POSTCODE":        "LOCATION",
+	"UK_SORT_CODE":       "FINANCIAL",
+	"UK_BANK_ACCOUNT":    "FINANCIAL",
+
+	"IBAN":           "FINANCIAL",
+	"SWIFT_BIC":      "FINANCIAL",
+	"EU_VAT":         "FINANCIAL",
+	"DE_TAX_ID":      "GOV_ID",
+	"FR_NIR":         "GOV_ID",
+	"ES_DNI":         "GOV_ID",
+	"ES_NIE":         "GOV_ID",
+	"IT_FISCAL_CODE": "GOV_ID",
+	"NL_BSN":         "GOV_ID",
+
+	"IN_AADHAAR": "GOV_ID",
+	"IN_PAN":     "GOV_ID",
+	"IN_GSTIN":   "FINANCIAL",
+	"IN_IFSC":    "FINANCIAL",
+	"IN_UPI_ID":  "FINANCIAL",
+	"PHONE_IN":   "PHONE",
+
+	"CA_SIN":         "GOV_ID",
+	"CA_POSTAL_CODE": "LOCATION",
+	"AU_TFN":         "GOV_ID",
+	"AU_MEDICARE":    "GOV_ID",
+	"AU_ABN":         "FINANCIAL",
+	"BR_CPF":         "GOV_ID",
+	"BR_CNPJ":        "FINANCIAL",
+
+	"CN_RESIDENT_ID": "GOV_ID",
+	"JP_MY_NUMBER":   "GOV_ID",
+	"KR_RRN":         "GOV_ID",
+	"SG_NRIC":        "GOV_ID",
+}
+
+// EntityAncestors returns the categories containing entityType, most specific first.
+func EntityAncestors(entityType string) []string {
+	var ancestors []string
+	for t, ok := entityParents[entityType]; ok; t, ok = entityParents[t] {
+		ancestors = append(ancestors, t)
+	}
+	return ancestors
+}
+
+// EntityIsA reports whether entityType is category or one of its descendants.
+func EntityIsA(entityType, category string) bool {
+	if entityType == category {
+		return true
+	}
+	for _, a := range EntityAncestors(entityType) {
+		if a == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,295 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// ConversationHeader carries the conversation whose pseudonyms a request should reuse.
+// When a request has none, a new conversation is started and its ID is returned in the
+// same response header. IDs are issued by the gateway and signed for the tenant that
+// started the conversation; any other value is rejected.
+const ConversationHeader = "X-Conversation-ID"
+
+const maxRequestBytes = 8 << 20
+
+// Config configures the gateway.
+type Config struct {
+	// UpstreamURL is the base URL of the OpenAI-compatible API, e.g. https://api.openai.com/v1.
+	UpstreamURL    string
+	UpstreamAPIKey string
+	// SessionTTL is how long an idle conversation keeps its pseudonyms.
+	SessionTTL time.Duration
+	Timeout    time.Duration
+	// Detection holds the detection settings applied to prompts. Its Text field is ignored.
+	Detection model.DetectionRequest
+}
+
+// Gateway is a reverse proxy for OpenAI-style chat completions. It replaces PII in
+// prompts with per-conversation placeholders before forwarding, and puts the original
+// values back into the model's answer, including streamed responses.
+type Gateway struct {
+	cfg      Config
+	detector redactor.TextDetector
+	redactor *redactor.Redactor
+	client   *http.Client
+
+	// secret signs conversation IDs. Sessions live in memory, so a per-process secret
+	// is enough: an ID from another instance names no session here either.
+	secret []byte
+
+	mu       sync.Mutex
+	sessions map[string]*session // Keyed by tenant and conversation ID
+}
+
+type session struct {
+	pseudonyms *redactor.Pseudonyms
+	lastUsed   time.Time
+}
+
+func New(cfg Config, detector redactor.TextDetector, redactor *redactor.Redactor) *Gateway {
+	if cfg.SessionTTL == 0 {
+		cfg.SessionTTL = time.Hour
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 5 * time.Minute
+	}
+	cfg.UpstreamURL = strings.TrimSuffix(cfg.UpstreamURL, "/")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("failed to generate conversation secret: %v", err))
+	}
+	return &Gateway{
+		cfg:      cfg,
+		detector: detector,
+		redactor: redactor,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		secret:   secret,
+		sessions: make(map[string]*session),
+	}
+}
+
+// ServeHTTP handles POST /v1/chat/completions.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	dec := json.NewDecoder(io.LimitReader(r.Body, maxRequestBytes))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tenantID := tenant.FromContext(r.Context())
+	conversationID := r.Header.Get(ConversationHeader)
+	if conversationID == "" {
+		conversationID = g.newConversationID(tenantID)
+	} else if !g.validConversationID(tenantID, conversationID) {
+		http.Error(w, "Invalid "+ConversationHeader+" header", http.StatusBadRequest)
+		return
+	}
+	pseudonyms := g.pseudonyms(tenantID + "/" + conversationID)
+
+	if err := g.redactMessages(r.Context(), body, pseudonyms); err != nil {
+		http.Error(w, "Detection failed", http.StatusInternalServerError)
+		return
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	upstreamReq, err := http.NewRequestWithContext(r.Context(), http.MethodPost, g.cfg.UpstreamURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		http.Error(w, "Upstream request failed", http.StatusBadGateway)
+		return
+	}
+	upstreamReq.Header.Set("Content-Type", "application/json")
+	if accept := r.Header.Get("Accept"); accept != "" {
+		upstreamReq.Header.Set("Accept", accept)
+	}
+	if g.cfg.UpstreamAPIKey != "" {
+		upstreamReq.Header.Set("Authorization", "Bearer "+g.cfg.UpstreamAPIKey)
+	}
+
+	res, err := g.client.Do(upstreamReq)
+	if err != nil {
+		log.Error().Err(err).Msg("LLM upstream request failed")
+		http.Error(w, "Upstream request failed", http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+
+	w.Header().Set(ConversationHeader, conversationID)
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType == "text/event-stream" {
+		g.streamResponse(w, res, pseudonyms)
+		return
+	}
+	g.copyResponse(w, res, pseudonyms)
+}
+
+// newConversationID returns a random ID followed by its signature for tenantID.
+func (g *Gateway) newConversationID(tenantID string) string {
+	id, _ := gonanoid.New()
+	return id + "." + g.sign(tenantID, id)
+}
+
+// validConversationID reports whether conversationID was issued to tenantID.
+func (g *Gateway) validConversationID(tenantID, conversationID string) bool {
+	id, signature, ok := strings.Cut(conversationID, ".")
+	return ok && hmac.Equal([]byte(signature), []byte(g.sign(tenantID, id)))
+}
+
+func (g *Gateway) sign(tenantID, id string) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(tenantID + "\x00" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// pseudonyms returns the placeholder set of a session, creating it if needed, and
+// evicts sessions idle for longer than the session TTL.
+func (g *Gateway) pseudonyms(key string) *redactor.Pseudonyms {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for id, s := range g.sessions {
+		if now.Sub(s.lastUsed) > g.cfg.SessionTTL {
+			delete(g.sessions, id)
+		}
+	}
+	s, ok := g.sessions[key]
+	if !ok {
+		s = &session{pseudonyms: redactor.NewPseudonyms()}
+		g.sessions[key] = s
+	}
+	s.lastUsed = now
+	return s.pseudonyms
+}
+
+// redactMessages pseudonymizes the text of every message, whether content is a plain
+// string or an array of content parts.
+func (g *Gateway) redactMessages(ctx context.Context, body map[string]any, p *redactor.Pseudonyms) error {
+	messages, _ := body["messages"].([]any)
+	for _, m := range messages {
+		msg, ok := m.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch content := msg["content"].(type) {
+		case string:
+			redacted, err := g.redactText(ctx, content, p)
+			if err != nil {
+				return err
+			}
+			msg["content"] = redacted
+		case []any:
+			for _, part := range content {
+				part, ok := part.(map[string]any)
+				if !ok || part["type"] != "text" {
+					continue
+				}
+				text, _ := part["text"].(string)
+				redacted, err := g.redactText(ctx, text, p)
+				if err != nil {
+					return err
+				}
+				part["text"] = redacted
+			}
+		}
+	}
+	return nil
+}
+
+func (g *Gateway) redactText(ctx context.Context, text string, p *redactor.Pseudonyms) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+	req := g.cfg.Detection
+	req.Text = text
+	detections, err := g.detector.Detect(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("prompt detection failed: %w", err)
+	}
+	return g.redactor.Pseudonymize(text, detections, p).RedactedText, nil
+}
+
+// copyResponse rehydrates a non-streamed completion's content and tool call arguments.
+// Error responses are passed through.
+func (g *Gateway) copyResponse(w http.ResponseWriter, res *http.Response, p *redactor.Pseudonyms) {
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		http.Error(w, "Upstream response failed", http.StatusBadGateway)
+		return
+	}
+
+	if res.StatusCode == http.StatusOK {
+		var completion map[string]any
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&completion); err == nil {
+			for _, choice := range choices(completion) {
+				if msg, ok := choice["message"].(map[string]any); ok {
+					if content, ok := msg["content"].(string); ok {
+						msg["content"] = p.Rehydrate(content)
+					}
+					for _, fn := range toolCallFunctions(msg) {
+						if arguments, ok := fn["arguments"].(string); ok {
+							fn["arguments"] = p.RehydrateJSON(arguments)
+						}
+					}
+				}
+			}
+			if rehydrated, err := json.Marshal(completion); err == nil {
+				data = rehydrated
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", res.Header.Get("Content-Type"))
+	w.WriteHeader(res.StatusCode)
+	w.Write(data)
+}
+
+func choices(v map[string]any) []map[string]any {
+	list, _ := v["choices"].([]any)
+	out := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if choice, ok := item.(map[string]any); ok {
+			out = append(out, choice)
+		}
+	}
+	return out
+}
+
+// toolCallFunctions returns the function of each of a message's or delta's tool calls.
+func toolCallFunctions(msg map[string]any) []map[string]any {
+	calls, _ := msg["tool_calls"].([]any)
+	var out []map[string]any
+	for _, item := range calls {
+		call, _ := item.(map[string]any)
+		if fn, ok := call["function"].(map[string]any); ok {
+			out = append(out, fn)
+		}
+	}
+	return out
+}
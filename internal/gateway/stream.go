@@ -0,0 +1,298 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+	"github.com/rs/zerolog/log"
+)
+
+const maxPlaceholderBytes = 64
+
+// streamRehydrator rehydrates a stream of content deltas. A placeholder can be split
+// across deltas ("<PER", "SON_1>"), so a trailing fragment that may still become a
+// placeholder is held back until the next delta arrives or the stream ends.
+type streamRehydrator struct {
+	pseudonyms *redactor.Pseudonyms
+	pending    string
+	json       bool // The deltas are JSON, as tool call arguments are
+}
+
+func (s *streamRehydrator) write(delta string) string {
+	text := s.pending + delta
+	cut := len(text)
+	if i := strings.LastIndexByte(text, '<'); i >= 0 && mayBecomePlaceholder(text[i:]) {
+		cut = i
+	}
+	if s.json {
+		// JSON may escape the placeholder's brackets. A fragment ending in part of an
+		// escaped '>' starts at the last backslash but one.
+		for i, n := len(text), 0; n < 2; n++ {
+			if i = strings.LastIndexByte(text[:i], '\\'); i < 0 {
+				break
+			}
+			if mayBecomeEscapedPlaceholder(text[i:]) {
+				cut = min(cut, i)
+			}
+		}
+	}
+	s.pending = text[cut:]
+	return s.rehydrate(text[:cut])
+}
+
+func (s *streamRehydrator) flush() string {
+	out := s.rehydrate(s.pending)
+	s.pending = ""
+	return out
+}
+
+func (s *streamRehydrator) rehydrate(text string) string {
+	if s.json {
+		return s.pseudonyms.RehydrateJSON(text)
+	}
+	return s.pseudonyms.Rehydrate(text)
+}
+
+// choiceRehydrator rehydrates one choice's content deltas and the argument deltas of
+// each of its tool calls.
+type choiceRehydrator struct {
+	content   *streamRehydrator
+	arguments map[string]*streamRehydrator // By tool call index
+}
+
+func (c *choiceRehydrator) toolCall(index string) *streamRehydrator {
+	if rh, ok := c.arguments[index]; ok {
+		return rh
+	}
+	rh := &streamRehydrator{pseudonyms: c.content.pseudonyms, json: true}
+	c.arguments[index] = rh
+	return rh
+}
+
+// write rehydrates delta in place. At the end of the choice, held back text is
+// added to it.
+func (c *choiceRehydrator) write(delta map[string]any, finished bool) {
+	content, _ := delta["content"].(string)
+	text := c.content.write(content)
+	calls, _ := delta["tool_calls"].([]any)
+	for _, item := range calls {
+		call, _ := item.(map[string]any)
+		fn, _ := call["function"].(map[string]any)
+		if arguments, ok := fn["arguments"].(string); ok {
+			fn["arguments"] = c.toolCall(toolCallIndex(call)).write(arguments)
+		}
+	}
+	if finished {
+		text += c.content.flush()
+		c.flushArguments(delta)
+	}
+	if _, ok := delta["content"].(string); ok || text != "" {
+		delta["content"] = text
+	}
+}
+
+// flush adds held back text to delta and reports whether there was any.
+func (c *choiceRehydrator) flush(delta map[string]any) bool {
+	if text := c.content.flush(); text != "" {
+		delta["content"] = text
+	}
+	c.flushArguments(delta)
+	return len(delta) > 0
+}
+
+func (c *choiceRehydrator) flushArguments(delta map[string]any) {
+	indexes := make([]string, 0, len(c.arguments))
+	for index := range c.arguments {
+		indexes = append(indexes, index)
+	}
+	sort.Strings(indexes)
+
+	for _, index := range indexes {
+		text := c.arguments[index].flush()
+		if text == "" {
+			continue
+		}
+		calls, _ := delta["tool_calls"].([]any)
+		appended := false
+		for _, item := range calls {
+			call, _ := item.(map[string]any)
+			fn, _ := call["function"].(map[string]any)
+			if arguments, ok := fn["arguments"].(string); ok && toolCallIndex(call) == index {
+				fn["arguments"] = arguments + text
+				appended = true
+				break
+			}
+		}
+		if !appended {
+			delta["tool_calls"] = append(calls, map[string]any{
+				"index":    json.Number(index),
+				"function": map[string]any{"arguments": text},
+			})
+		}
+	}
+}
+
+func toolCallIndex(call map[string]any) string {
+	if n, ok := call["index"].(json.Number); ok {
+		return n.String()
+	}
+	return "0"
+}
+
+// mayBecomePlaceholder reports whether fragment, starting with '<', is an unterminated
+// prefix of a placeholder.
+func mayBecomePlaceholder(fragment string) bool {
+	if len(fragment) > maxPlaceholderBytes {
+		return false
+	}
+	for _, r := range fragment[1:] {
+		if !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// mayBecomeEscapedPlaceholder is mayBecomePlaceholder for a fragment of JSON that
+// starts with a backslash and writes the brackets as \u003c and \u003e.
+func mayBecomeEscapedPlaceholder(fragment string) bool {
+	const lt, gt = `\u003c`, `\u003e`
+	if len(fragment) > maxPlaceholderBytes+len(lt)+len(gt) {
+		return false
+	}
+	n := min(len(fragment), len(lt))
+	if !strings.EqualFold(fragment[:n], lt[:n]) {
+		return false
+	}
+	rest := fragment[n:]
+	i := strings.IndexFunc(rest, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_')
+	})
+	if i < 0 {
+		return true
+	}
+	tail := rest[i:]
+	return len(tail) < len(gt) && strings.EqualFold(tail, gt[:len(tail)])
+}
+
+// streamResponse relays a server-sent event stream of chat completion chunks,
+// rehydrating each choice's content and tool call argument deltas.
+func (g *Gateway) streamResponse(w http.ResponseWriter, res *http.Response, p *redactor.Pseudonyms) {
+	w.Header().Set("Content-Type", res.Header.Get("Content-Type"))
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(res.StatusCode)
+	flusher, _ := w.(http.Flusher)
+
+	rehydrators := map[string]*choiceRehydrator{}
+	rehydrator := func(index string) *choiceRehydrator {
+		if rh, ok := rehydrators[index]; ok {
+			return rh
+		}
+		rh := &choiceRehydrator{content: &streamRehydrator{pseudonyms: p}, arguments: map[string]*streamRehydrator{}}
+		rehydrators[index] = rh
+		return rh
+	}
+
+	var last map[string]any
+	flushPending := func() {
+		if last == nil {
+			return
+		}
+		indexes := make([]string, 0, len(rehydrators))
+		for index := range rehydrators {
+			indexes = append(indexes, index)
+		}
+		sort.Strings(indexes)
+
+		var pending []any
+		for _, index := range indexes {
+			if delta := map[string]any{}; rehydrators[index].flush(delta) {
+				pending = append(pending, map[string]any{
+					"index":         json.Number(index),
+					"delta":         delta,
+					"finish_reason": nil,
+				})
+			}
+		}
+		if len(pending) == 0 {
+			return
+		}
+		last["choices"] = pending
+		writeEvent(w, last)
+	}
+
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 64*1024), maxRequestBytes)
+	done := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		data, isData := strings.CutPrefix(line, "data:")
+		if !isData {
+			w.Write([]byte(line + "\n"))
+			if line == "" && flusher != nil {
+				flusher.Flush()
+			}
+			continue
+		}
+
+		data = strings.TrimPrefix(data, " ")
+		if data == "[DONE]" {
+			flushPending()
+			done = true
+			w.Write([]byte(line + "\n"))
+			continue
+		}
+
+		var chunk map[string]any
+		dec := json.NewDecoder(strings.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&chunk); err != nil {
+			w.Write([]byte(line + "\n"))
+			continue
+		}
+		for _, choice := range choices(chunk) {
+			index := "0"
+			if n, ok := choice["index"].(json.Number); ok {
+				index = n.String()
+			}
+			delta, _ := choice["delta"].(map[string]any)
+			if delta == nil {
+				continue
+			}
+			rehydrator(index).write(delta, choice["finish_reason"] != nil)
+		}
+		last = chunk
+		writeEventData(w, chunk)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Error().Err(err).Msg("LLM upstream stream failed")
+	}
+	if !done {
+		flushPending()
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes a complete event. writeEventData writes only the data line,
+// leaving the event terminator to the relayed stream.
+func writeEvent(w http.ResponseWriter, v any) {
+	writeEventData(w, v)
+	w.Write([]byte("\n"))
+}
+
+func writeEventData(w http.ResponseWriter, v any) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return
+	}
+	w.Write([]byte("data: " + strings.TrimSuffix(buf.String(), "\n") + "\n"))
+}
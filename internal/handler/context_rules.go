@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+	"github.com/asoasis/pii-redaction-api/internal/tenantconfig"
+)
+
+// ContextRulesHandler manages the context rule overrides of the tenant named by the request.
+type ContextRulesHandler struct {
+	rules *tenantconfig.RuleService
+}
+
+func NewContextRulesHandler(rules *tenantconfig.RuleService) *ContextRulesHandler {
+	return &ContextRulesHandler{rules: rules}
+}
+
+// Get returns the tenant's overrides and the effective rule set.
+func (h *ContextRulesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID := tenant.FromContext(r.Context())
+	overrides, err := h.rules.Overrides(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, "Failed to load context rules", http.StatusInternalServerError)
+		return
+	}
+	if overrides == nil {
+		overrides = []model.ContextRule{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"overrides": overrides,
+		"effective": h.rules.Effective(tenantID),
+	})
+}
+
+func (h *ContextRulesHandler) Put(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Overrides []model.ContextRule `json:"overrides"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.rules.Put(r.Context(), tenant.FromContext(r.Context()), body.Overrides); err != nil {
+		if errors.Is(err, detector.ErrInvalidContextRule) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to save context rules", http.StatusInternalServerError)
+		return
+	}
+	h.Get(w, r)
+}
+
+func (h *ContextRulesHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.rules.Put(r.Context(), tenant.FromContext(r.Context()), nil); err != nil {
+		http.Error(w, "Failed to delete context rules", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
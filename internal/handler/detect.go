@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
@@ -33,29 +34,42 @@ func (h *DetectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	detections, err := h.pipeline.Detect(r.Context(), req)
+	result, err := h.pipeline.Analyze(r.Context(), req)
+	if errors.Is(err, detector.ErrInvalidRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Detection failed: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	offsets := detector.NewOffsetConverter(req.Text, req.OffsetUnit)
+	offsets.Detections(result.Detections)
+	offsets.Detections(result.Dropped)
+
+	detections := result.Detections
 	res := model.DetectionResponse{
 		EntitiesFound:    len(detections),
 		Detections:       detections,
+		Dropped:          result.Dropped,
+		Locales:          result.Locales,
 		ProcessingTimeMs: time.Since(start).Milliseconds(),
 		RequestID:        "",
 	}
 	res.RequestID, _ = gonanoid.New()
 
-	// Calculate RiskSummary
+	// Calculate RiskSummary by category, so locale-specific types count like their
+	// US counterparts.
 	for _, d := range detections {
-		switch d.EntityType {
-		case "SSN", "PHONE_US", "PERSON", "DATE":
+		t := d.EntityType
+		switch {
+		case detector.EntityIsA(t, "GOV_ID"), detector.EntityIsA(t, "PHONE"), detector.EntityIsA(t, "PERSON"), detector.EntityIsA(t, "DATE"):
 			res.RiskSummary.HIPAARelevant++
 			res.RiskSummary.GDPRRelevant++
-		case "EMAIL", "LOCATION":
+		case detector.EntityIsA(t, "CONTACT"), detector.EntityIsA(t, "LOCATION"):
 			res.RiskSummary.GDPRRelevant++
-		case "CREDIT_CARD":
+		case detector.EntityIsA(t, "CREDIT_CARD"):
 			res.RiskSummary.PCIRelevant++
 		}
 	}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/har"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type HARHandler struct {
+	har *har.Redactor
+}
+
+func NewHARHandler(har *har.Redactor) *HARHandler {
+	return &HARHandler{har: har}
+}
+
+func (h *HARHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	var req model.CaptureRedactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.HAR) == 0 {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	redacted, stats, err := h.har.RedactHAR(r.Context(), req.HAR, captureOptions(req))
+	if errors.Is(err, har.ErrInvalidHAR) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if errors.Is(err, detector.ErrInvalidRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("HAR redaction failed")
+		http.Error(w, "HAR redaction failed", http.StatusInternalServerError)
+		return
+	}
+
+	writeCaptureResponse(w, model.CaptureRedactionResponse{HAR: redacted}, stats, start)
+}
+
+type HTTPTranscriptHandler struct {
+	har *har.Redactor
+}
+
+func NewHTTPTranscriptHandler(har *har.Redactor) *HTTPTranscriptHandler {
+	return &HTTPTranscriptHandler{har: har}
+}
+
+func (h *HTTPTranscriptHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	var req model.CaptureRedactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	redacted, stats, err := h.har.RedactTranscript(r.Context(), req.Text, captureOptions(req))
+	if err != nil {
+		http.Error(w, "Redaction failed", http.StatusInternalServerError)
+		return
+	}
+
+	writeCaptureResponse(w, model.CaptureRedactionResponse{RedactedText: redacted}, stats, start)
+}
+
+func captureOptions(req model.CaptureRedactionRequest) har.Options {
+	return har.Options{
+		HeaderDenylist: req.HeaderDenylist,
+		CookieDenylist: req.CookieDenylist,
+		Request:        req.RedactionRequest,
+	}
+}
+
+func writeCaptureResponse(w http.ResponseWriter, res model.CaptureRedactionResponse, stats har.Stats, start time.Time) {
+	res.EntitiesFound = stats.EntitiesFound
+	res.HeadersRedacted = stats.HeadersRedacted
+	res.CookiesRedacted = stats.CookiesRedacted
+	res.ProcessingTimeMs = time.Since(start).Milliseconds()
+	res.RequestID, _ = gonanoid.New()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/asoasis/pii-redaction-api/internal/logline"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/rs/zerolog/log"
+)
+
+// LogsHandler streams NDJSON or logfmt lines through the log line processor.
+// Options are taken from query parameters and fall back to the configured defaults.
+type LogsHandler struct {
+	processor *logline.Processor
+	defaults  logline.Options
+}
+
+func NewLogsHandler(processor *logline.Processor, defaults logline.Options) *LogsHandler {
+	return &LogsHandler{processor: processor, defaults: defaults}
+}
+
+func (h *LogsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	opts, err := h.options(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/x-ndjson"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Trailer", "X-Lines-Processed, X-Lines-Dropped, X-Entities-Found, X-Error")
+
+	out := &countingWriter{w: w}
+	stats, err := h.processor.Process(r.Context(), r.Body, out, opts)
+	if err != nil {
+		log.Error().Err(err).Msg("Log redaction failed")
+		if out.n == 0 {
+			w.Header().Del("Trailer")
+			http.Error(w, "Log redaction failed", http.StatusInternalServerError)
+			return
+		}
+		// The lines before the error have been sent, so only the trailer can report it.
+		w.Header().Set("X-Error", err.Error())
+	}
+	w.Header().Set("X-Lines-Processed", strconv.Itoa(stats.Lines))
+	w.Header().Set("X-Lines-Dropped", strconv.Itoa(stats.Dropped))
+	w.Header().Set("X-Entities-Found", strconv.Itoa(stats.EntitiesFound))
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
+
+func (h *LogsHandler) options(r *http.Request) (logline.Options, error) {
+	q := r.URL.Query()
+	opts := h.defaults
+
+	if v := q.Get("format"); v != "" {
+		opts.Format = logline.Format(v)
+	}
+	switch opts.Format {
+	case "", logline.FormatAuto, logline.FormatNDJSON, logline.FormatLogfmt:
+	default:
+		return opts, errors.New("invalid query parameter: format")
+	}
+	if v := q.Get("malformed"); v != "" {
+		opts.Malformed = logline.MalformedPolicy(v)
+	}
+	if opts.Malformed != logline.MalformedPass && opts.Malformed != logline.MalformedDrop {
+		return opts, errors.New("invalid query parameter: malformed")
+	}
+	if q.Has("redact_fields") {
+		opts.RedactFields = splitList(q.Get("redact_fields"))
+	}
+	if q.Has("message_fields") {
+		opts.MessageFields = splitList(q.Get("message_fields"))
+	}
+
+	opts.Request = model.RedactionRequest{
+		DetectionRequest: model.DetectionRequest{
+			Locale:      q.Get("locale"),
+			EntityTypes: splitList(q.Get("entity_types")),
+		},
+		Mode: model.RedactionMode(q.Get("mode")),
+	}
+	if v := q.Get("confidence_threshold"); v != "" {
+		threshold, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return opts, errors.New("invalid query parameter: confidence_threshold")
+		}
+		opts.Request.ConfidenceThreshold = threshold
+	}
+	return opts, nil
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
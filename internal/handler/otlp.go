@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/asoasis/pii-redaction-api/internal/otlp"
+	"github.com/rs/zerolog/log"
+)
+
+const maxOTLPBodyBytes = 16 << 20
+
+// OTLPLogsHandler is an OTLP/HTTP logs receiver that redacts and re-exports every request.
+// Both the JSON and the protobuf encodings are accepted and re-exported as received.
+type OTLPLogsHandler struct {
+	processor *otlp.Processor
+	exporter  *otlp.Exporter
+}
+
+func NewOTLPLogsHandler(processor *otlp.Processor, exporter *otlp.Exporter) *OTLPLogsHandler {
+	return &OTLPLogsHandler{processor: processor, exporter: exporter}
+}
+
+func (h *OTLPLogsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	redact := h.processor.RedactLogs
+	switch mediaType {
+	case "application/json":
+	case "application/x-protobuf":
+		redact = h.processor.RedactLogsProto
+	default:
+		http.Error(w, "Unsupported content type: OTLP must be application/json or application/x-protobuf", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "Invalid gzip body", http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxOTLPBodyBytes))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	redacted, stats, err := redact(r.Context(), data)
+	if err != nil {
+		http.Error(w, "Redaction failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.exporter.Export(r.Context(), mediaType, redacted); err != nil {
+		log.Error().Err(err).Msg("OTLP re-export failed")
+		var exportErr *otlp.ExportError
+		if errors.As(err, &exportErr) && exportErr.StatusCode < 500 {
+			http.Error(w, "Downstream rejected export", exportErr.StatusCode)
+			return
+		}
+		// 502/503 are retryable for OTLP clients.
+		http.Error(w, "Downstream export failed", http.StatusServiceUnavailable)
+		return
+	}
+
+	log.Debug().Int("log_records", stats.LogRecords).Int("entities_found", stats.EntitiesFound).Msg("OTLP logs redacted")
+	// An empty ExportLogsServiceResponse is an empty protobuf message.
+	w.Header().Set("Content-Type", mediaType)
+	if mediaType == "application/json" {
+		w.Write([]byte("{}"))
+	}
+}
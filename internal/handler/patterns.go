@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+	"github.com/asoasis/pii-redaction-api/internal/tenantconfig"
+	"github.com/go-chi/chi/v5"
+)
+
+// PatternsHandler manages the custom regex patterns of the tenant named by the request.
+type PatternsHandler struct {
+	patterns *tenantconfig.PatternService
+}
+
+func NewPatternsHandler(patterns *tenantconfig.PatternService) *PatternsHandler {
+	return &PatternsHandler{patterns: patterns}
+}
+
+func (h *PatternsHandler) List(w http.ResponseWriter, r *http.Request) {
+	patterns, err := h.patterns.List(r.Context(), tenant.FromContext(r.Context()))
+	if err != nil {
+		http.Error(w, "Failed to load patterns", http.StatusInternalServerError)
+		return
+	}
+	if patterns == nil {
+		patterns = []model.CustomPattern{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"patterns": patterns})
+}
+
+func (h *PatternsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var p model.CustomPattern
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.patterns.Create(r.Context(), tenant.FromContext(r.Context()), p); err != nil {
+		writePatternError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (h *PatternsHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var p model.CustomPattern
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	name := chi.URLParam(r, "name")
+	if p.Name == "" {
+		p.Name = name
+	}
+	if err := h.patterns.Update(r.Context(), tenant.FromContext(r.Context()), name, p); err != nil {
+		writePatternError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (h *PatternsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.patterns.Delete(r.Context(), tenant.FromContext(r.Context()), chi.URLParam(r, "name")); err != nil {
+		writePatternError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PatternsHandler) Test(w http.ResponseWriter, r *http.Request) {
+	var req model.PatternTestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	matches, err := h.patterns.Test(req.Pattern, req.Text)
+	if err != nil {
+		writePatternError(w, err)
+		return
+	}
+	if matches == nil {
+		matches = []model.Detection{}
+	}
+	writeJSON(w, http.StatusOK, model.PatternTestResponse{Matches: matches})
+}
+
+func writePatternError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, detector.ErrInvalidPattern), errors.Is(err, tenantconfig.ErrTooManyPatterns):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, tenantconfig.ErrPatternNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, tenantconfig.ErrPatternExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Failed to save pattern", http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+	"github.com/asoasis/pii-redaction-api/internal/tenantconfig"
+)
+
+// PolicyHandler manages the detection policy of the tenant named by the request.
+type PolicyHandler struct {
+	policies *tenantconfig.PolicyService
+}
+
+func NewPolicyHandler(policies *tenantconfig.PolicyService) *PolicyHandler {
+	return &PolicyHandler{policies: policies}
+}
+
+func (h *PolicyHandler) Get(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.policies.Get(r.Context(), tenant.FromContext(r.Context()))
+	if err != nil {
+		http.Error(w, "Failed to load policy", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, policy)
+}
+
+func (h *PolicyHandler) Put(w http.ResponseWriter, r *http.Request) {
+	var policy model.DetectionPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.policies.Put(r.Context(), tenant.FromContext(r.Context()), policy); err != nil {
+		if errors.Is(err, tenantconfig.ErrInvalidPolicy) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to save policy", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, policy)
+}
+
+func (h *PolicyHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.policies.Put(r.Context(), tenant.FromContext(r.Context()), model.DetectionPolicy{}); err != nil {
+		http.Error(w, "Failed to delete policy", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
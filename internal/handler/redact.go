@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -31,18 +32,27 @@ func (h *RedactHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	detections, err := h.pipeline.Detect(r.Context(), req.DetectionRequest)
+	result, err := h.pipeline.Analyze(r.Context(), req.DetectionRequest)
+	if errors.Is(err, detector.ErrInvalidRequest) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Detection failed", http.StatusInternalServerError)
 		return
 	}
 
-	res, err := h.redactor.Redact(r.Context(), req.Text, detections, req.Mode, req.TTL)
+	res, err := h.redactor.Redact(r.Context(), req.Text, result.Detections, req.Mode, req.TTL)
 	if err != nil {
 		http.Error(w, "Redaction failed", http.StatusInternalServerError)
 		return
 	}
 
+	offsets := detector.NewOffsetConverter(req.Text, req.OffsetUnit)
+	offsets.RedactionDetails(res.Detections)
+	offsets.Detections(result.Dropped)
+	res.Dropped = result.Dropped
+	res.Locales = result.Locales
 	res.ProcessingTimeMs = time.Since(start).Milliseconds()
 	res.RequestID, _ = gonanoid.New()
 
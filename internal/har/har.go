@@ -0,0 +1,443 @@
+package har
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+)
+
+const redactedValue = "[REDACTED]"
+
+// DefaultHeaderDenylist lists headers whose values are always redacted.
+var DefaultHeaderDenylist = []string{
+	"authorization",
+	"proxy-authorization",
+	"cookie",
+	"set-cookie",
+	"x-api-key",
+	"x-auth-token",
+	"x-csrf-token",
+	"x-xsrf-token",
+	"x-amz-security-token",
+}
+
+// ErrInvalidHAR is returned for input that is not a HAR archive.
+var ErrInvalidHAR = errors.New("invalid HAR")
+
+// DefaultCookieDenylist redacts every cookie value.
+var DefaultCookieDenylist = []string{"*"}
+
+// Options controls what gets redacted in a capture.
+type Options struct {
+	HeaderDenylist []string
+	CookieDenylist []string
+	// Request carries the detection and redaction settings applied to URLs, headers and bodies.
+	// Its Text field is ignored.
+	Request model.RedactionRequest
+}
+
+// Stats counts what was redacted in a capture.
+type Stats struct {
+	EntitiesFound   int
+	HeadersRedacted int
+	CookiesRedacted int
+}
+
+// Redactor scrubs HAR archives and raw HTTP transcripts.
+type Redactor struct {
+	detector redactor.TextDetector
+	redactor *redactor.Redactor
+}
+
+func NewRedactor(detector redactor.TextDetector, redactor *redactor.Redactor) *Redactor {
+	return &Redactor{detector: detector, redactor: redactor}
+}
+
+// session holds the per-call options and counters.
+type session struct {
+	*Redactor
+	opts    Options
+	headers map[string]bool
+	cookies map[string]bool
+	stats   Stats
+}
+
+func (h *Redactor) newSession(opts Options) *session {
+	if opts.HeaderDenylist == nil {
+		opts.HeaderDenylist = DefaultHeaderDenylist
+	}
+	if opts.CookieDenylist == nil {
+		opts.CookieDenylist = DefaultCookieDenylist
+	}
+	return &session{
+		Redactor: h,
+		opts:     opts,
+		headers:  lowerSet(opts.HeaderDenylist),
+		cookies:  lowerSet(opts.CookieDenylist),
+	}
+}
+
+// RedactHAR redacts a HAR 1.2 archive. Fields it does not know about are kept as-is,
+// so the result still loads in browser devtools.
+func (h *Redactor) RedactHAR(ctx context.Context, data []byte, opts Options) ([]byte, Stats, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var root map[string]any
+	if err := dec.Decode(&root); err != nil {
+		return nil, Stats{}, fmt.Errorf("%w: %v", ErrInvalidHAR, err)
+	}
+	log, ok := root["log"].(map[string]any)
+	if !ok {
+		return nil, Stats{}, fmt.Errorf("%w: missing log object", ErrInvalidHAR)
+	}
+
+	s := h.newSession(opts)
+	for _, p := range objects(log["pages"]) {
+		if err := s.redactStringField(ctx, p, "title", s.redactURL); err != nil {
+			return nil, s.stats, err
+		}
+	}
+	for _, entry := range objects(log["entries"]) {
+		if req, ok := entry["request"].(map[string]any); ok {
+			if err := s.redactRequest(ctx, req); err != nil {
+				return nil, s.stats, err
+			}
+		}
+		if res, ok := entry["response"].(map[string]any); ok {
+			if err := s.redactResponse(ctx, res); err != nil {
+				return nil, s.stats, err
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return nil, s.stats, err
+	}
+	return buf.Bytes(), s.stats, nil
+}
+
+func (s *session) redactRequest(ctx context.Context, req map[string]any) error {
+	if err := s.redactStringField(ctx, req, "url", s.redactURL); err != nil {
+		return err
+	}
+	if err := s.redactHeaders(ctx, req["headers"]); err != nil {
+		return err
+	}
+	s.redactCookies(req["cookies"])
+	if err := s.redactNameValues(ctx, req["queryString"]); err != nil {
+		return err
+	}
+
+	postData, ok := req["postData"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	if err := s.redactNameValues(ctx, postData["params"]); err != nil {
+		return err
+	}
+	mimeType, _ := postData["mimeType"].(string)
+	return s.redactStringField(ctx, postData, "text", func(ctx context.Context, text string) (string, error) {
+		return s.redactBody(ctx, text, mimeType)
+	})
+}
+
+func (s *session) redactResponse(ctx context.Context, res map[string]any) error {
+	if err := s.redactHeaders(ctx, res["headers"]); err != nil {
+		return err
+	}
+	s.redactCookies(res["cookies"])
+	if err := s.redactStringField(ctx, res, "redirectURL", s.redactURL); err != nil {
+		return err
+	}
+
+	content, ok := res["content"].(map[string]any)
+	if !ok {
+		return nil
+	}
+	mimeType, _ := content["mimeType"].(string)
+	if !isTextual(mimeType) {
+		return nil
+	}
+	encoding, _ := content["encoding"].(string)
+	return s.redactStringField(ctx, content, "text", func(ctx context.Context, text string) (string, error) {
+		if encoding != "base64" {
+			return s.redactBody(ctx, text, mimeType)
+		}
+		raw, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			// Leave undecodable content alone rather than corrupting it.
+			return text, nil
+		}
+		redacted, err := s.redactBody(ctx, string(raw), mimeType)
+		if err != nil {
+			return "", err
+		}
+		return base64.StdEncoding.EncodeToString([]byte(redacted)), nil
+	})
+}
+
+func (s *session) redactHeaders(ctx context.Context, v any) error {
+	for _, header := range objects(v) {
+		name, _ := header["name"].(string)
+		if s.headers[strings.ToLower(name)] {
+			if _, ok := header["value"].(string); ok {
+				header["value"] = redactedValue
+				s.stats.HeadersRedacted++
+			}
+			continue
+		}
+		if err := s.redactStringField(ctx, header, "value", func(ctx context.Context, value string) (string, error) {
+			return s.redactHeaderValue(ctx, name, value)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *session) redactCookies(v any) {
+	for _, cookie := range objects(v) {
+		name, _ := cookie["name"].(string)
+		if !s.cookieDenied(name) {
+			continue
+		}
+		if _, ok := cookie["value"].(string); ok {
+			cookie["value"] = redactedValue
+			s.stats.CookiesRedacted++
+		}
+	}
+}
+
+func (s *session) redactNameValues(ctx context.Context, v any) error {
+	for _, param := range objects(v) {
+		if err := s.redactStringField(ctx, param, "value", s.redactText); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactStringField replaces obj[key] with fn(obj[key]) when it holds a string.
+func (s *session) redactStringField(ctx context.Context, obj map[string]any, key string, fn func(context.Context, string) (string, error)) error {
+	value, ok := obj[key].(string)
+	if !ok || value == "" {
+		return nil
+	}
+	redacted, err := fn(ctx, value)
+	if err != nil {
+		return err
+	}
+	obj[key] = redacted
+	return nil
+}
+
+func (s *session) redactHeaderValue(ctx context.Context, name, value string) (string, error) {
+	switch strings.ToLower(name) {
+	case "referer", "location", "origin", "content-location", ":path":
+		return s.redactURL(ctx, value)
+	case "cookie", "set-cookie":
+		return s.redactCookieHeader(ctx, name, value)
+	}
+	return s.redactText(ctx, value)
+}
+
+// redactCookieHeader applies the cookie denylist to a Cookie or Set-Cookie header the
+// header denylist let through, as redactCookies does to a HAR entry's cookies. Only the
+// first pair of a Set-Cookie is a cookie; its attributes are kept.
+func (s *session) redactCookieHeader(ctx context.Context, name, value string) (string, error) {
+	pairs := strings.Split(value, ";")
+	n := len(pairs)
+	if strings.EqualFold(name, "set-cookie") {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		cookie, v, ok := strings.Cut(pairs[i], "=")
+		if !ok {
+			continue
+		}
+		if s.cookieDenied(strings.TrimSpace(cookie)) {
+			v = redactedValue
+			s.stats.CookiesRedacted++
+		} else {
+			var err error
+			if v, err = s.redactText(ctx, v); err != nil {
+				return "", err
+			}
+		}
+		pairs[i] = cookie + "=" + v
+	}
+	return strings.Join(pairs, ";"), nil
+}
+
+// redactURL redacts the userinfo, path, query values and fragment of a URL.
+// Query parameter order and names are preserved.
+func (s *session) redactURL(ctx context.Context, raw string) (string, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme == "" && u.Host == "" && !strings.HasPrefix(raw, "/")) {
+		return s.redactText(ctx, raw)
+	}
+
+	if u.User != nil {
+		u.User = url.User(redactedValue)
+	}
+	path, err := s.redactText(ctx, u.Path)
+	if err != nil {
+		return "", err
+	}
+	if path != u.Path {
+		u.Path, u.RawPath = path, ""
+	}
+	if u.RawQuery, err = s.redactQuery(ctx, u.RawQuery); err != nil {
+		return "", err
+	}
+	if u.Fragment, err = s.redactText(ctx, u.Fragment); err != nil {
+		return "", err
+	}
+	u.RawFragment = ""
+	return u.String(), nil
+}
+
+// redactQuery redacts the values of an application/x-www-form-urlencoded string.
+func (s *session) redactQuery(ctx context.Context, query string) (string, error) {
+	if query == "" {
+		return query, nil
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		decoded, err := url.QueryUnescape(value)
+		if err != nil {
+			decoded = value
+		}
+		redacted, err := s.redactText(ctx, decoded)
+		if err != nil {
+			return "", err
+		}
+		if redacted != decoded {
+			pairs[i] = key + "=" + url.QueryEscape(redacted)
+		}
+	}
+	return strings.Join(pairs, "&"), nil
+}
+
+// redactBody redacts a request or response body according to its MIME type.
+func (s *session) redactBody(ctx context.Context, body, mimeType string) (string, error) {
+	mimeType = strings.ToLower(mimeType)
+	switch {
+	case strings.Contains(mimeType, "json") && json.Valid([]byte(body)):
+		return s.redactJSON(ctx, body)
+	case strings.Contains(mimeType, "x-www-form-urlencoded"):
+		return s.redactQuery(ctx, body)
+	}
+	return s.redactText(ctx, body)
+}
+
+// redactJSON redacts every string value in a JSON document, keeping object keys.
+func (s *session) redactJSON(ctx context.Context, body string) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return "", err
+	}
+	doc, err := s.redactJSONValue(ctx, doc)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func (s *session) redactJSONValue(ctx context.Context, v any) (any, error) {
+	var err error
+	switch val := v.(type) {
+	case string:
+		return s.redactText(ctx, val)
+	case map[string]any:
+		for k, child := range val {
+			if val[k], err = s.redactJSONValue(ctx, child); err != nil {
+				return nil, err
+			}
+		}
+	case []any:
+		for i, child := range val {
+			if val[i], err = s.redactJSONValue(ctx, child); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return v, nil
+}
+
+// redactText runs the detection pipeline and redactor over a single string.
+func (s *session) redactText(ctx context.Context, text string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+	req := s.opts.Request
+	req.Text = text
+	res, err := s.redactor.RedactText(ctx, s.detector, req)
+	if err != nil {
+		return "", err
+	}
+	s.stats.EntitiesFound += res.EntitiesFound
+	return res.RedactedText, nil
+}
+
+func (s *session) cookieDenied(name string) bool {
+	return s.cookies["*"] || s.cookies[strings.ToLower(name)]
+}
+
+func isTextual(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	if mimeType == "" || strings.HasPrefix(mimeType, "text/") {
+		return true
+	}
+	for _, sub := range []string{"json", "xml", "javascript", "x-www-form-urlencoded", "graphql"} {
+		if strings.Contains(mimeType, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func objects(v any) []map[string]any {
+	list, _ := v.([]any)
+	out := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if obj, ok := item.(map[string]any); ok {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+func lowerSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[strings.ToLower(v)] = true
+	}
+	return set
+}
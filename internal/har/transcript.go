@@ -0,0 +1,112 @@
+package har
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+var (
+	requestLine = regexp.MustCompile(`^([A-Z]+) (\S+) (HTTP/\d(?:\.\d)?)$`)
+	statusLine  = regexp.MustCompile(`^HTTP/\d(?:\.\d)? \d{3}\b`)
+	headerLine  = regexp.MustCompile("^(:?[!#$%&'*+\\-.^_`|~0-9A-Za-z]+):[ \\t]*(.*)$")
+)
+
+// RedactTranscript redacts a raw HTTP transcript such as curl -v output or a proxy dump.
+// It may contain several request and response messages; each is split into its start
+// line, headers and body, and the line structure of the input is preserved.
+func (h *Redactor) RedactTranscript(ctx context.Context, transcript string, opts Options) (string, Stats, error) {
+	s := h.newSession(opts)
+
+	var (
+		out         strings.Builder
+		body        strings.Builder
+		inHeaders   bool
+		contentType string
+	)
+	flushBody := func() error {
+		if body.Len() == 0 {
+			return nil
+		}
+		text := body.String()
+		trimmed := strings.TrimRight(text, " \t\r\n")
+		redacted, err := s.redactBody(ctx, trimmed, contentType)
+		if err != nil {
+			return err
+		}
+		out.WriteString(redacted + text[len(trimmed):])
+		body.Reset()
+		return nil
+	}
+
+	for _, line := range strings.SplitAfter(transcript, "\n") {
+		content, eol := splitEOL(line)
+
+		if m := requestLine.FindStringSubmatch(content); m != nil {
+			if err := flushBody(); err != nil {
+				return "", s.stats, err
+			}
+			target, err := s.redactURL(ctx, m[2])
+			if err != nil {
+				return "", s.stats, err
+			}
+			out.WriteString(m[1] + " " + target + " " + m[3] + eol)
+			inHeaders, contentType = true, ""
+			continue
+		}
+		if statusLine.MatchString(content) {
+			if err := flushBody(); err != nil {
+				return "", s.stats, err
+			}
+			out.WriteString(line)
+			inHeaders, contentType = true, ""
+			continue
+		}
+
+		if !inHeaders {
+			body.WriteString(line)
+			continue
+		}
+		if content == "" {
+			out.WriteString(line)
+			inHeaders = false
+			continue
+		}
+		m := headerLine.FindStringSubmatch(content)
+		if m == nil {
+			// Not a header after all; treat the rest of the message as body.
+			inHeaders = false
+			body.WriteString(line)
+			continue
+		}
+		name, value := m[1], m[2]
+		if strings.EqualFold(name, "content-type") {
+			contentType = value
+		}
+		if s.headers[strings.ToLower(name)] {
+			value = redactedValue
+			s.stats.HeadersRedacted++
+		} else {
+			var err error
+			if value, err = s.redactHeaderValue(ctx, name, value); err != nil {
+				return "", s.stats, err
+			}
+		}
+		out.WriteString(name + ": " + value + eol)
+	}
+
+	if err := flushBody(); err != nil {
+		return "", s.stats, err
+	}
+	return out.String(), s.stats, nil
+}
+
+func splitEOL(line string) (string, string) {
+	if strings.HasSuffix(line, "\r\n") {
+		return line[:len(line)-2], "\r\n"
+	}
+	if strings.HasSuffix(line, "\n") {
+		return line[:len(line)-1], "\n"
+	}
+	return line, ""
+}
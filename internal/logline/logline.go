@@ -0,0 +1,181 @@
+package logline
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+)
+
+// Format identifies how log lines are encoded.
+type Format string
+
+const (
+	FormatAuto   Format = "auto"
+	FormatNDJSON Format = "ndjson"
+	FormatLogfmt Format = "logfmt"
+)
+
+// MalformedPolicy decides what happens to lines that cannot be parsed.
+type MalformedPolicy string
+
+const (
+	MalformedPass MalformedPolicy = "pass"
+	MalformedDrop MalformedPolicy = "drop"
+)
+
+const (
+	redactedValue = "[REDACTED]"
+	maxLineBytes  = 1 << 20
+)
+
+// DefaultMessageFields are the fields run through the detector pipeline when none are configured.
+var DefaultMessageFields = []string{"msg", "message"}
+
+// Options controls how a stream of log lines is processed.
+type Options struct {
+	Format Format
+	// RedactFields are replaced with [REDACTED] without running detection.
+	RedactFields []string
+	// MessageFields are run through the detector pipeline and redactor.
+	MessageFields []string
+	Malformed     MalformedPolicy
+	// Request carries the detection and redaction settings for message fields. Its Text field is ignored.
+	Request model.RedactionRequest
+}
+
+// Stats summarises a processed stream.
+type Stats struct {
+	Lines          int
+	Malformed      int
+	Dropped        int
+	FieldsRedacted int
+	EntitiesFound  int
+}
+
+// Processor redacts structured log lines.
+type Processor struct {
+	detector redactor.TextDetector
+	redactor *redactor.Redactor
+}
+
+func NewProcessor(detector redactor.TextDetector, redactor *redactor.Redactor) *Processor {
+	return &Processor{detector: detector, redactor: redactor}
+}
+
+// Process reads newline-delimited log lines from r and writes the redacted lines to w
+// in the same format. Lines that need no changes are written back byte for byte. On
+// error, the lines redacted so far are still written.
+func (p *Processor) Process(ctx context.Context, r io.Reader, w io.Writer, opts Options) (stats Stats, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+	out := bufio.NewWriter(w)
+	defer func() {
+		if flushErr := out.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		stats.Lines++
+		line, keep, err := p.processLine(ctx, strings.TrimSuffix(scanner.Text(), "\r"), opts, &stats)
+		if err != nil {
+			return stats, fmt.Errorf("line %d: %w", stats.Lines, err)
+		}
+		if !keep {
+			stats.Dropped++
+			continue
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return stats, scanner.Err()
+}
+
+// ProcessLine redacts a single log line. keep is false when the line is malformed
+// and the policy is to drop it.
+func (p *Processor) ProcessLine(ctx context.Context, line string, opts Options) (redacted string, keep bool, err error) {
+	var stats Stats
+	return p.processLine(ctx, line, opts, &stats)
+}
+
+func (p *Processor) processLine(ctx context.Context, line string, opts Options, stats *Stats) (string, bool, error) {
+	if strings.TrimSpace(line) == "" {
+		return line, true, nil
+	}
+
+	format := opts.Format
+	if format == "" || format == FormatAuto {
+		format = FormatLogfmt
+		if strings.HasPrefix(strings.TrimSpace(line), "{") {
+			format = FormatNDJSON
+		}
+	}
+
+	var rec record
+	var err error
+	switch format {
+	case FormatNDJSON:
+		rec, err = parseJSON(line)
+	case FormatLogfmt:
+		rec, err = parseLogfmt(line)
+	default:
+		return "", false, fmt.Errorf("unsupported format %q", format)
+	}
+	if err != nil {
+		stats.Malformed++
+		return line, opts.Malformed != MalformedDrop, nil
+	}
+
+	redactFields := fieldSet(opts.RedactFields)
+	messageFields := fieldSet(opts.MessageFields)
+	if len(opts.MessageFields) == 0 {
+		messageFields = fieldSet(DefaultMessageFields)
+	}
+
+	changed := false
+	for i, f := range rec.fields {
+		switch {
+		case redactFields[strings.ToLower(f.key)]:
+			rec.setString(i, redactedValue)
+			stats.FieldsRedacted++
+			changed = true
+		case messageFields[strings.ToLower(f.key)]:
+			value, ok := rec.getString(i)
+			if !ok || strings.TrimSpace(value) == "" {
+				continue
+			}
+			req := opts.Request
+			req.Text = value
+			res, err := p.redactor.RedactText(ctx, p.detector, req)
+			if err != nil {
+				return "", false, err
+			}
+			if res.EntitiesFound > 0 {
+				stats.EntitiesFound += res.EntitiesFound
+				rec.setString(i, res.RedactedText)
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return line, true, nil
+	}
+	return rec.encode(), true, nil
+}
+
+func fieldSet(fields []string) map[string]bool {
+	set := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		set[strings.ToLower(strings.TrimSpace(f))] = true
+	}
+	return set
+}
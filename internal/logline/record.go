@@ -0,0 +1,203 @@
+package logline
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var errMalformed = errors.New("malformed log line")
+
+// field is a single key/value pair. For NDJSON, value holds the raw JSON encoding;
+// for logfmt it holds the unquoted value.
+type field struct {
+	key   string
+	value string
+	bare  bool // logfmt key without "="
+}
+
+// record is a parsed log line that keeps its fields in their original order.
+type record struct {
+	format Format
+	fields []field
+}
+
+func parseJSON(line string) (record, error) {
+	rec := record{format: FormatNDJSON}
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return rec, errMalformed
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return rec, errMalformed
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return rec, errMalformed
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return rec, errMalformed
+		}
+		rec.fields = append(rec.fields, field{key: key, value: string(raw)})
+	}
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('}') {
+		return rec, errMalformed
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return rec, errMalformed
+	}
+	return rec, nil
+}
+
+func parseLogfmt(line string) (record, error) {
+	rec := record{format: FormatLogfmt}
+	i := 0
+	for i < len(line) {
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		if i == len(line) {
+			break
+		}
+
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' && line[i] != '"' {
+			i++
+		}
+		key := line[start:i]
+		if key == "" {
+			return rec, errMalformed
+		}
+		if i == len(line) || line[i] == ' ' {
+			rec.fields = append(rec.fields, field{key: key, bare: true})
+			continue
+		}
+		if line[i] == '"' {
+			return rec, errMalformed
+		}
+		i++ // '='
+
+		if i < len(line) && line[i] == '"' {
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return rec, errMalformed
+			}
+			value, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				return rec, errMalformed
+			}
+			rec.fields = append(rec.fields, field{key: key, value: value})
+			i = end + 1
+			if i < len(line) && line[i] != ' ' {
+				return rec, errMalformed
+			}
+			continue
+		}
+
+		start = i
+		for i < len(line) && line[i] != ' ' {
+			i++
+		}
+		rec.fields = append(rec.fields, field{key: key, value: line[start:i]})
+	}
+	if len(rec.fields) == 0 {
+		return rec, errMalformed
+	}
+	for _, f := range rec.fields {
+		if !f.bare {
+			return rec, nil
+		}
+	}
+	// A line of bare words is free text, not logfmt.
+	return rec, errMalformed
+}
+
+// getString returns the string value of field i, if it is a string.
+func (r *record) getString(i int) (string, bool) {
+	f := r.fields[i]
+	if r.format == FormatLogfmt {
+		return f.value, !f.bare
+	}
+	var s string
+	if err := json.Unmarshal([]byte(f.value), &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// setString replaces the value of field i with s, whatever its previous type.
+func (r *record) setString(i int, s string) {
+	f := &r.fields[i]
+	if r.format == FormatLogfmt {
+		f.value, f.bare = s, false
+		return
+	}
+	f.value = jsonString(s)
+}
+
+func (r *record) encode() string {
+	var b strings.Builder
+	if r.format == FormatNDJSON {
+		b.WriteByte('{')
+		for i, f := range r.fields {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%s:%s", jsonString(f.key), f.value)
+		}
+		b.WriteByte('}')
+		return b.String()
+	}
+
+	for i, f := range r.fields {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.key)
+		if f.bare {
+			continue
+		}
+		b.WriteByte('=')
+		if needsQuoting(f.value) {
+			b.WriteString(strconv.Quote(f.value))
+		} else {
+			b.WriteString(f.value)
+		}
+	}
+	return b.String()
+}
+
+func jsonString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
@@ -1,11 +1,47 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
 )
 
-func Auth(apiKey string) func(http.Handler) http.Handler {
+// APIKeys maps each API key to the tenant it authenticates. The tenant a request acts
+// as comes from its key, so a caller cannot reach another tenant's configuration.
+type APIKeys map[string]string
+
+// NewAPIKeys returns defaultKey for the default tenant, plus tenantKeys, which maps
+// tenant IDs to their keys.
+func NewAPIKeys(defaultKey string, tenantKeys map[string]string) (APIKeys, error) {
+	keys := make(APIKeys, len(tenantKeys)+1)
+	if defaultKey != "" {
+		keys[defaultKey] = tenant.Default
+	}
+	for id, key := range tenantKeys {
+		if !tenant.ValidID(id) {
+			return nil, fmt.Errorf("invalid tenant ID %q", id)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("tenant %s has an empty API key", id)
+		}
+		if other, ok := keys[key]; ok {
+			return nil, fmt.Errorf("tenants %s and %s share an API key", other, id)
+		}
+		keys[key] = id
+	}
+	return keys, nil
+}
+
+// tenant returns the tenant of the request's bearer token, if it is a known key.
+func (k APIKeys) tenant(r *http.Request) (string, bool) {
+	id, ok := k[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	return id, ok
+}
+
+// Auth rejects requests without a valid API key and binds the rest to the key's tenant.
+func Auth(keys APIKeys) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -14,13 +50,13 @@ func Auth(apiKey string) func(http.Handler) http.Handler {
 				return
 			}
 
-			token := strings.TrimPrefix(authHeader, "Bearer ")
-			if token != apiKey {
+			id, ok := keys.tenant(r)
+			if !ok {
 				http.Error(w, "Invalid API Key", http.StatusUnauthorized)
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(tenant.WithID(r.Context(), id)))
 		})
 	}
 }
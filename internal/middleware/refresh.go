@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+	"github.com/asoasis/pii-redaction-api/internal/tenantconfig"
+	"github.com/rs/zerolog/log"
+)
+
+// RefreshConfig brings the caller's tenant config up to date with the config store
+// before the request is handled. It must run after Auth or Tenant.
+func RefreshConfig(refresher *tenantconfig.Refresher) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := tenant.FromContext(r.Context())
+			if err := refresher.Refresh(r.Context(), id); err != nil {
+				log.Warn().Err(err).Str("tenant", id).Msg("Failed to refresh tenant config; using the loaded config")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+)
+
+// Tenant is for routes open to anonymous callers. Requests with a valid API key run as
+// the key's tenant, and the rest as the default tenant.
+func Tenant(keys APIKeys) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id, ok := keys.tenant(r); ok {
+				r = r.WithContext(tenant.WithID(r.Context(), id))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package model
+
+import "encoding/json"
+
+// CaptureRedactionRequest represents the input for HAR and raw HTTP transcript redaction.
+// /v1/redact/har reads the archive from HAR; /v1/redact/http reads the transcript from Text.
+type CaptureRedactionRequest struct {
+	RedactionRequest
+	HAR            json.RawMessage `json:"har,omitempty"`
+	HeaderDenylist []string        `json:"header_denylist,omitempty"` // Replaces the default header denylist
+	CookieDenylist []string        `json:"cookie_denylist,omitempty"` // Replaces the default cookie denylist, "*" matches every cookie
+}
+
+// CaptureRedactionResponse represents the output of HAR and raw HTTP transcript redaction.
+type CaptureRedactionResponse struct {
+	HAR              json.RawMessage `json:"har,omitempty"`
+	RedactedText     string          `json:"redacted_text,omitempty"`
+	EntitiesFound    int             `json:"entities_found"`
+	HeadersRedacted  int             `json:"headers_redacted"`
+	CookiesRedacted  int             `json:"cookies_redacted"`
+	ProcessingTimeMs int64           `json:"processing_time_ms"`
+	RequestID        string          `json:"request_id"`
+}
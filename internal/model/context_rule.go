@@ -0,0 +1,33 @@
+package model
+
+// ContextRuleAction is what a context rule does to a detection when it fires.
+type ContextRuleAction string
+
+const (
+	BoostAction      ContextRuleAction = "boost"
+	PenaltyAction    ContextRuleAction = "penalty"
+	ReclassifyAction ContextRuleAction = "reclassify"
+	SuppressAction   ContextRuleAction = "suppress"
+)
+
+// ContextRule adjusts detections of one entity type based on the words around them.
+//
+// A rule fires when at least one Keywords entry appears in the window (if any are set),
+// at least one TextKeywords entry appears in the detected text itself (if any are set),
+// and no NegativeKeywords entry appears in the window. Keywords match whole words,
+// case-insensitively; multi-word keywords match consecutive words.
+type ContextRule struct {
+	ID               string            `json:"id" yaml:"id"`
+	EntityType       string            `json:"entity_type" yaml:"entity_type"`
+	Keywords         []string          `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	NegativeKeywords []string          `json:"negative_keywords,omitempty" yaml:"negative_keywords,omitempty"`
+	TextKeywords     []string          `json:"text_keywords,omitempty" yaml:"text_keywords,omitempty"`
+	Window           int               `json:"window,omitempty" yaml:"window,omitempty"`
+	Unit             string            `json:"unit,omitempty" yaml:"unit,omitempty"`           // words (default) or sentences
+	Direction        string            `json:"direction,omitempty" yaml:"direction,omitempty"` // before, after or both (default)
+	Action           ContextRuleAction `json:"action" yaml:"action"`
+	Amount           float64           `json:"amount,omitempty" yaml:"amount,omitempty"`               // Confidence change for boost and penalty
+	ReclassifyAs     string            `json:"reclassify_as,omitempty" yaml:"reclassify_as,omitempty"` // Target entity type for reclassify
+	// Disabled turns off a built-in rule with the same ID in a tenant override.
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
@@ -7,22 +7,84 @@ type Detection struct {
 	Start           int     `json:"start"`          // Start offset in original text
 	End             int     `json:"end"`            // End offset in original text
 	Confidence      float64 `json:"confidence"`
-	DetectionMethod string  `json:"detection_method"`
+	DetectionMethod string  `json:"detection_method"` // Method of the most confident contributor
+	// Methods lists every detection method that found this span.
+	Methods []string `json:"detection_methods,omitempty"`
+	ID      string   `json:"id,omitempty"`     // Set when the request asks for nested detections
+	Parent  string   `json:"parent,omitempty"` // ID of the overlapping detection chosen over this one
+	// EntityID links mentions of the same person found by coreference, e.g. e1.
+	EntityID string `json:"entity_id,omitempty"`
+	// Metadata holds detector-specific facts about the value, such as the E.164 form
+	// of a phone number under "e164".
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Explanation is set when the request asks for it.
+	Explanation *Explanation `json:"explanation,omitempty"`
 }
 
+// EnsembleAggregation combines the confidences of detectors that found the same span.
+type EnsembleAggregation string
+
+const (
+	NoisyOrAggregation      EnsembleAggregation = "noisy_or"
+	WeightedVoteAggregation EnsembleAggregation = "weighted_vote"
+	MaxAggregation          EnsembleAggregation = "max"
+)
+
+// OverlapStrategy decides which of several overlapping detections is kept.
+type OverlapStrategy string
+
+const (
+	HighestConfidenceOverlap OverlapStrategy = "highest_confidence"
+	LongestOverlap           OverlapStrategy = "longest"
+	PriorityOverlap          OverlapStrategy = "priority"
+)
+
+// OffsetUnit is the unit of the offsets in responses.
+type OffsetUnit string
+
+const (
+	ByteOffsets  OffsetUnit = "bytes" // UTF-8 bytes, as Go indexes strings
+	RuneOffsets  OffsetUnit = "runes" // Unicode code points, as Python indexes strings
+	UTF16Offsets OffsetUnit = "utf16" // UTF-16 code units, as JavaScript and Java index strings
+)
+
 // DetectionRequest represents the input for PII detection.
 type DetectionRequest struct {
-	Text                string   `json:"text"`
-	Locale              string   `json:"locale,omitempty"`
+	Text string `json:"text"`
+	// Locale picks the pattern pack. "auto" identifies the text's languages and picks
+	// packs for them.
+	Locale string `json:"locale,omitempty"`
+	// Locales runs several packs at once, in addition to Locale. It may contain "auto".
+	Locales             []string `json:"locales,omitempty"`
 	EntityTypes         []string `json:"entity_types,omitempty"`
 	ConfidenceThreshold float64  `json:"confidence_threshold,omitempty"`
+	// EntityThresholds overrides ConfidenceThreshold per entity type or category;
+	// the most specific match wins.
+	EntityThresholds map[string]float64 `json:"entity_thresholds,omitempty"`
+	OverlapStrategy  OverlapStrategy    `json:"overlap_strategy,omitempty"` // Default highest_confidence
+	// EntityPriority ranks entity types or categories for the priority strategy, highest first.
+	EntityPriority []string `json:"entity_priority,omitempty"`
+	// IncludeNested also returns the overlapping detections that lost, each linked to the
+	// winner by Parent. Redaction always uses only the winners.
+	IncludeNested bool `json:"include_nested,omitempty"`
+	// EnsembleAggregation overrides the server's aggregation for agreeing detectors.
+	EnsembleAggregation EnsembleAggregation `json:"ensemble_aggregation,omitempty"`
+	// Explain attaches an Explanation to every detection and returns dropped candidates.
+	Explain bool `json:"explain,omitempty"`
+	// Transcript also matches numbers, email addresses and URLs read out in English, as
+	// in speech-to-text output.
+	Transcript bool `json:"transcript,omitempty"`
+	// OffsetUnit selects the unit of the offsets in the response. Default bytes.
+	OffsetUnit OffsetUnit `json:"offset_unit,omitempty"`
 }
 
 // DetectionResponse represents the output of PII detection.
 type DetectionResponse struct {
 	EntitiesFound    int         `json:"entities_found"`
 	Detections       []Detection `json:"detections"`
+	Dropped          []Detection `json:"dropped,omitempty"` // Discarded candidates, in explain mode
 	RiskSummary      RiskSummary `json:"risk_summary,omitempty"`
+	Locales          []string    `json:"locales,omitempty"` // Pattern packs the request was run with
 	ProcessingTimeMs int64       `json:"processing_time_ms"`
 	RequestID        string      `json:"request_id"`
 }
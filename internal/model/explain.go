@@ -0,0 +1,50 @@
+package model
+
+// Explanation records how a detection was produced. It is only filled in when the
+// request sets Explain.
+type Explanation struct {
+	Source          string        `json:"source"`                     // Detection method that produced the candidate
+	Pattern         string        `json:"pattern,omitempty"`          // ID of the regex pattern, e.g. en-US/SSN
+	Validator       string        `json:"validator,omitempty"`        // Validator the pattern applies, e.g. luhn
+	ValidatorResult string        `json:"validator_result,omitempty"` // passed or failed
+	Normalized      string        `json:"normalized,omitempty"`       // Matched text after normalization, when it differs
+	BaseConfidence  float64       `json:"base_confidence"`
+	Contributors    []Contributor `json:"contributors,omitempty"` // Agreeing detections merged into this one
+	Adjustments     []Adjustment  `json:"adjustments,omitempty"`
+	Decisions       []Decision    `json:"decisions,omitempty"`
+}
+
+// Contributor is one detector's finding merged into an ensemble detection.
+type Contributor struct {
+	Method     string  `json:"method"`
+	EntityType string  `json:"entity_type"`
+	Confidence float64 `json:"confidence"`
+	Pattern    string  `json:"pattern,omitempty"`
+}
+
+// Adjustment is a change to a detection's confidence or entity type.
+type Adjustment struct {
+	Stage            string  `json:"stage"`             // pattern_keyword, ensemble or context_rule
+	RuleID           string  `json:"rule_id,omitempty"` // Context rule that fired
+	Action           string  `json:"action"`            // boost, penalty, reclassify, suppress or aggregate
+	Keyword          string  `json:"keyword,omitempty"` // Keyword that made the rule fire
+	ConfidenceBefore float64 `json:"confidence_before"`
+	ConfidenceAfter  float64 `json:"confidence_after"`
+	EntityType       string  `json:"entity_type,omitempty"` // New entity type for reclassify
+}
+
+// Decision records a pipeline stage keeping or dropping a candidate.
+type Decision struct {
+	Stage   string `json:"stage"`   // validator, context_rule, entity_filter, threshold or overlap
+	Outcome string `json:"outcome"` // kept or dropped
+	Reason  string `json:"reason"`
+}
+
+// DetectionResult is the full output of a detection run.
+type DetectionResult struct {
+	Detections []Detection
+	// Dropped holds the candidates the pipeline discarded, when the request sets Explain.
+	Dropped []Detection
+	// Locales are the pattern packs used, after resolving "auto", primary first.
+	Locales []string
+}
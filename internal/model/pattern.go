@@ -0,0 +1,22 @@
+package model
+
+// CustomPattern is a tenant-defined regex entity type.
+type CustomPattern struct {
+	Name            string   `json:"name"` // Entity type reported for matches, e.g. EMPLOYEE_ID
+	Regex           string   `json:"regex"`
+	Confidence      float64  `json:"confidence"`
+	Validator       string   `json:"validator,omitempty"` // Name of a built-in validator, e.g. luhn
+	ContextKeywords []string `json:"context_keywords,omitempty"`
+	Description     string   `json:"description,omitempty"`
+}
+
+// PatternTestRequest tests a pattern against sample text without saving it.
+type PatternTestRequest struct {
+	Pattern CustomPattern `json:"pattern"`
+	Text    string        `json:"text"`
+}
+
+// PatternTestResponse lists the matches a pattern produced.
+type PatternTestResponse struct {
+	Matches []Detection `json:"matches"`
+}
@@ -0,0 +1,8 @@
+package model
+
+// DetectionPolicy holds a tenant's default confidence thresholds. Thresholds set in a
+// request take precedence over the policy.
+type DetectionPolicy struct {
+	ConfidenceThreshold float64            `json:"confidence_threshold,omitempty"`
+	EntityThresholds    map[string]float64 `json:"entity_thresholds,omitempty"` // Keyed by entity type or category
+}
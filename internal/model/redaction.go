@@ -24,6 +24,8 @@ type RedactionResponse struct {
 	RedactedText     string            `json:"redacted_text"`
 	EntitiesFound    int               `json:"entities_found"`
 	Detections       []RedactionDetail `json:"detections"`
+	Dropped          []Detection       `json:"dropped,omitempty"` // Discarded candidates, in explain mode
+	Locales          []string          `json:"locales,omitempty"` // Pattern packs the request was run with
 	ProcessingTimeMs int64             `json:"processing_time_ms"`
 	RequestID        string            `json:"request_id"`
 }
@@ -36,6 +38,9 @@ type RedactionDetail struct {
 	RedactedValue   string  `json:"redacted_value"`
 	Confidence      float64 `json:"confidence"`
 	DetectionMethod string  `json:"detection_method"`
+	// DetectionMethods lists every detection method that found this span.
+	DetectionMethods []string     `json:"detection_methods,omitempty"`
+	Explanation      *Explanation `json:"explanation,omitempty"`
 }
 
 // TokenMapping maps a token to its original PII value.
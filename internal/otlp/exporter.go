@@ -0,0 +1,66 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ExportError is returned when the downstream endpoint rejects an export.
+type ExportError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ExportError) Error() string {
+	return fmt.Sprintf("OTLP export rejected with status %d: %s", e.StatusCode, e.Body)
+}
+
+// Exporter re-exports redacted payloads to an OTLP/HTTP logs endpoint.
+type Exporter struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+}
+
+// NewExporter creates an exporter for endpoint, the full logs URL
+// (for example http://collector:4318/v1/logs).
+func NewExporter(endpoint string, headers map[string]string, timeout time.Duration) *Exporter {
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	return &Exporter{
+		endpoint: endpoint,
+		headers:  headers,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Export sends an ExportLogsServiceRequest downstream in the encoding named by
+// contentType, application/json or application/x-protobuf.
+func (e *Exporter) Export(ctx context.Context, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build OTLP export request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range e.headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("OTLP export failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return &ExportError{StatusCode: res.StatusCode, Body: string(msg)}
+	}
+	io.Copy(io.Discard, res.Body)
+	return nil
+}
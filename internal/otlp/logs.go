@@ -0,0 +1,148 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+)
+
+// Options controls which parts of a log record are redacted.
+type Options struct {
+	// Attributes lists the log record attribute keys run through the pipeline. "*" selects all.
+	Attributes []string
+	// Request carries the detection and redaction settings. Its Text field is ignored.
+	Request model.RedactionRequest
+}
+
+// Stats counts what was redacted in an export request.
+type Stats struct {
+	LogRecords    int
+	EntitiesFound int
+}
+
+// Processor redacts OTLP ExportLogsServiceRequest payloads in the OTLP/JSON or
+// protobuf encoding.
+// Log bodies are always redacted; attributes only when selected in Options.
+// Everything else, including resource and scope attributes, is passed through unchanged.
+type Processor struct {
+	detector redactor.TextDetector
+	redactor *redactor.Redactor
+	opts     Options
+}
+
+func NewProcessor(detector redactor.TextDetector, redactor *redactor.Redactor, opts Options) *Processor {
+	return &Processor{detector: detector, redactor: redactor, opts: opts}
+}
+
+// RedactLogs redacts an ExportLogsServiceRequest and returns the re-encoded request.
+func (p *Processor) RedactLogs(ctx context.Context, data []byte) ([]byte, Stats, error) {
+	var stats Stats
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var req map[string]any
+	if err := dec.Decode(&req); err != nil {
+		return nil, stats, fmt.Errorf("invalid ExportLogsServiceRequest: %w", err)
+	}
+
+	attrs := p.attributeSet()
+	for _, resourceLogs := range objects(req["resourceLogs"]) {
+		for _, scopeLogs := range objects(resourceLogs["scopeLogs"]) {
+			for _, record := range objects(scopeLogs["logRecords"]) {
+				stats.LogRecords++
+				if body, ok := record["body"].(map[string]any); ok {
+					if err := p.redactAnyValue(ctx, body, &stats); err != nil {
+						return nil, stats, err
+					}
+				}
+				for _, kv := range objects(record["attributes"]) {
+					key, _ := kv["key"].(string)
+					if !attrs["*"] && !attrs[key] {
+						continue
+					}
+					if value, ok := kv["value"].(map[string]any); ok {
+						if err := p.redactAnyValue(ctx, value, &stats); err != nil {
+							return nil, stats, err
+						}
+					}
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(req); err != nil {
+		return nil, stats, err
+	}
+	return buf.Bytes(), stats, nil
+}
+
+// attributeSet returns the log record attribute keys to redact.
+func (p *Processor) attributeSet() map[string]bool {
+	attrs := make(map[string]bool, len(p.opts.Attributes))
+	for _, key := range p.opts.Attributes {
+		attrs[key] = true
+	}
+	return attrs
+}
+
+// redactAnyValue redacts the string leaves of an OTLP AnyValue in place.
+func (p *Processor) redactAnyValue(ctx context.Context, v map[string]any, stats *Stats) error {
+	if s, ok := v["stringValue"].(string); ok {
+		redacted, err := p.redactText(ctx, s, stats)
+		if err != nil {
+			return err
+		}
+		v["stringValue"] = redacted
+		return nil
+	}
+	if kvlist, ok := v["kvlistValue"].(map[string]any); ok {
+		for _, kv := range objects(kvlist["values"]) {
+			if value, ok := kv["value"].(map[string]any); ok {
+				if err := p.redactAnyValue(ctx, value, stats); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if array, ok := v["arrayValue"].(map[string]any); ok {
+		for _, value := range objects(array["values"]) {
+			if err := p.redactAnyValue(ctx, value, stats); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Processor) redactText(ctx context.Context, text string, stats *Stats) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return text, nil
+	}
+	req := p.opts.Request
+	req.Text = text
+	res, err := p.redactor.RedactText(ctx, p.detector, req)
+	if err != nil {
+		return "", err
+	}
+	stats.EntitiesFound += res.EntitiesFound
+	return res.RedactedText, nil
+}
+
+func objects(v any) []map[string]any {
+	list, _ := v.([]any)
+	out := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		if obj, ok := item.(map[string]any); ok {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
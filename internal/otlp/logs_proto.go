@@ -0,0 +1,70 @@
+package otlp
+
+import (
+	"context"
+	"fmt"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// RedactLogsProto is RedactLogs for the OTLP/protobuf encoding.
+func (p *Processor) RedactLogsProto(ctx context.Context, data []byte) ([]byte, Stats, error) {
+	var stats Stats
+	var req collogspb.ExportLogsServiceRequest
+	if err := proto.Unmarshal(data, &req); err != nil {
+		return nil, stats, fmt.Errorf("invalid ExportLogsServiceRequest: %w", err)
+	}
+
+	attrs := p.attributeSet()
+	for _, resourceLogs := range req.ResourceLogs {
+		for _, scopeLogs := range resourceLogs.ScopeLogs {
+			for _, record := range scopeLogs.LogRecords {
+				stats.LogRecords++
+				if err := p.redactProtoValue(ctx, record.Body, &stats); err != nil {
+					return nil, stats, err
+				}
+				for _, kv := range record.Attributes {
+					if !attrs["*"] && !attrs[kv.Key] {
+						continue
+					}
+					if err := p.redactProtoValue(ctx, kv.Value, &stats); err != nil {
+						return nil, stats, err
+					}
+				}
+			}
+		}
+	}
+
+	out, err := proto.Marshal(&req)
+	if err != nil {
+		return nil, stats, err
+	}
+	return out, stats, nil
+}
+
+// redactProtoValue redacts the string leaves of an AnyValue in place.
+func (p *Processor) redactProtoValue(ctx context.Context, v *commonpb.AnyValue, stats *Stats) error {
+	switch value := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		redacted, err := p.redactText(ctx, value.StringValue, stats)
+		if err != nil {
+			return err
+		}
+		value.StringValue = redacted
+	case *commonpb.AnyValue_KvlistValue:
+		for _, kv := range value.KvlistValue.GetValues() {
+			if err := p.redactProtoValue(ctx, kv.Value, stats); err != nil {
+				return err
+			}
+		}
+	case *commonpb.AnyValue_ArrayValue:
+		for _, item := range value.ArrayValue.GetValues() {
+			if err := p.redactProtoValue(ctx, item, stats); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
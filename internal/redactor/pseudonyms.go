@@ -0,0 +1,85 @@
+package redactor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var placeholderPattern = regexp.MustCompile(`<[A-Z][A-Z0-9_]*_\d+>`)
+
+// escapedPlaceholderPattern matches a placeholder in JSON with < and > escaped, as
+// encoders such as Go's write them.
+var escapedPlaceholderPattern = regexp.MustCompile(`(?i:\\u003c)([A-Z][A-Z0-9_]*_\d+)(?i:\\u003e)`)
+
+// Pseudonyms assigns stable placeholders such as <PERSON_1> to PII values. The same
+// value always maps to the same placeholder, and placeholders can be mapped back with
+// Rehydrate. It is safe for concurrent use.
+type Pseudonyms struct {
+	mu            sync.Mutex
+	byValue       map[string]string
+	byPlaceholder map[string]string
+	counts        map[string]int
+}
+
+func NewPseudonyms() *Pseudonyms {
+	return &Pseudonyms{
+		byValue:       make(map[string]string),
+		byPlaceholder: make(map[string]string),
+		counts:        make(map[string]int),
+	}
+}
+
+// placeholder returns the placeholder for the value identified by canonical, which
+// is rehydrated to value as first seen.
+func (p *Pseudonyms) placeholder(entityType, canonical, value string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := entityType + "\x00" + canonical
+	if ph, ok := p.byValue[key]; ok {
+		return ph
+	}
+	p.counts[entityType]++
+	ph := fmt.Sprintf("<%s_%d>", entityType, p.counts[entityType])
+	p.byValue[key] = ph
+	p.byPlaceholder[ph] = value
+	return ph
+}
+
+// Rehydrate replaces every known placeholder in text with its original value.
+// Unknown placeholders are left untouched.
+func (p *Pseudonyms) Rehydrate(text string) string {
+	return p.rehydrate(text, func(value string) string { return value })
+}
+
+// RehydrateJSON is Rehydrate for JSON text, such as a tool call's arguments. Values
+// are escaped for a JSON string, which is where placeholders appear.
+func (p *Pseudonyms) RehydrateJSON(text string) string {
+	text = escapedPlaceholderPattern.ReplaceAllString(text, "<$1>")
+	return p.rehydrate(text, func(value string) string {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(value); err != nil {
+			return value
+		}
+		quoted := strings.TrimSuffix(buf.String(), "\n")
+		return quoted[1 : len(quoted)-1]
+	})
+}
+
+func (p *Pseudonyms) rehydrate(text string, escape func(string) string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return placeholderPattern.ReplaceAllStringFunc(text, func(ph string) string {
+		if value, ok := p.byPlaceholder[ph]; ok {
+			return escape(value)
+		}
+		return ph
+	})
+}
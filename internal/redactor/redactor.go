@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/asoasis/pii-redaction-api/internal/model"
 	"github.com/asoasis/pii-redaction-api/internal/store"
@@ -25,6 +27,22 @@ func (r *Redactor) Redact(ctx context.Context, text string, detections []model.D
 		ttlHours = 24
 	}
 
+	return redact(text, detections, func(det model.Detection) (string, error) {
+		return r.applyMode(ctx, det, mode, ttlHours)
+	})
+}
+
+// Pseudonymize replaces each detection with its placeholder in p, so repeated values
+// get the same placeholder across calls sharing p.
+func (r *Redactor) Pseudonymize(text string, detections []model.Detection, p *Pseudonyms) model.RedactionResponse {
+	res, _ := redact(text, detections, func(det model.Detection) (string, error) {
+		return p.placeholder(det.EntityType, canonical(det), det.Text), nil
+	})
+	return res
+}
+
+func redact(text string, detections []model.Detection, replace func(model.Detection) (string, error)) (model.RedactionResponse, error) {
+	detections = cover(detections)
 	res := model.RedactionResponse{
 		EntitiesFound: len(detections),
 		Detections:    make([]model.RedactionDetail, 0, len(detections)),
@@ -34,7 +52,7 @@ func (r *Redactor) Redact(ctx context.Context, text string, detections []model.D
 	redactedText := text
 	for i := len(detections) - 1; i >= 0; i-- {
 		det := detections[i]
-		redactedValue, err := r.applyMode(ctx, det, mode, ttlHours)
+		redactedValue, err := replace(det)
 		if err != nil {
 			return res, err
 		}
@@ -42,12 +60,14 @@ func (r *Redactor) Redact(ctx context.Context, text string, detections []model.D
 		redactedText = redactedText[:det.Start] + redactedValue + redactedText[det.End:]
 
 		res.Detections = append(res.Detections, model.RedactionDetail{
-			EntityType:      det.EntityType,
-			OriginalStart:   det.Start,
-			OriginalEnd:     det.End,
-			RedactedValue:   redactedValue,
-			Confidence:      det.Confidence,
-			DetectionMethod: det.DetectionMethod,
+			EntityType:       det.EntityType,
+			OriginalStart:    det.Start,
+			OriginalEnd:      det.End,
+			RedactedValue:    redactedValue,
+			Confidence:       det.Confidence,
+			DetectionMethod:  det.DetectionMethod,
+			DetectionMethods: det.Methods,
+			Explanation:      det.Explanation,
 		})
 	}
 
@@ -55,14 +75,39 @@ func (r *Redactor) Redact(ctx context.Context, text string, detections []model.D
 	return res, nil
 }
 
+// cover returns the non-overlapping detections to replace, sorted by start. Nested
+// detections are dropped; of any other overlapping pair, the earlier (then longer) wins.
+func cover(detections []model.Detection) []model.Detection {
+	sorted := make([]model.Detection, 0, len(detections))
+	for _, det := range detections {
+		if det.Parent == "" {
+			sorted = append(sorted, det)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Start != sorted[j].Start {
+			return sorted[i].Start < sorted[j].Start
+		}
+		return sorted[i].End > sorted[j].End
+	})
+
+	var covered []model.Detection
+	for _, det := range sorted {
+		if len(covered) == 0 || det.Start >= covered[len(covered)-1].End {
+			covered = append(covered, det)
+		}
+	}
+	return covered
+}
+
 func (r *Redactor) applyMode(ctx context.Context, det model.Detection, mode model.RedactionMode, ttlHours int) (string, error) {
 	switch mode {
 	case model.MaskMode:
-		return strings.Repeat("*", len(det.Text)), nil
+		return mask(det), nil
 	case model.ReplaceMode:
 		return "[" + det.EntityType + "]", nil
 	case model.HashMode:
-		hash := sha256.Sum256([]byte(det.Text))
+		hash := sha256.Sum256([]byte(canonical(det)))
 		return hex.EncodeToString(hash[:8]), nil
 	case model.TokenizeMode:
 		token, err := gonanoid.New()
@@ -85,6 +130,41 @@ func (r *Redactor) applyMode(ctx context.Context, det model.Detection, mode mode
 	}
 }
 
+// canonical returns the value hashing and pseudonyms are derived from: the E.164 form
+// of phone numbers, so "(415) 555-2671" and "+1 415 555 2671" get the same one, and
+// the matched text otherwise.
+func canonical(det model.Detection) string {
+	if e164 := det.Metadata["e164"]; e164 != "" {
+		return e164
+	}
+	return det.Text
+}
+
+// maskKeepLast is how many trailing digits mask mode leaves visible, by entity type.
+// UIDAI only allows the last four digits of an Aadhaar number to be displayed.
+var maskKeepLast = map[string]int{"IN_AADHAAR": 4}
+
+// mask replaces every character with *. For types in maskKeepLast, only digits are
+// replaced, so separators and the visible digits keep their place.
+func mask(det model.Detection) string {
+	keep, ok := maskKeepLast[det.EntityType]
+	if !ok {
+		return strings.Repeat("*", utf8.RuneCountInString(det.Text))
+	}
+	out := []rune(det.Text)
+	for i := len(out) - 1; i >= 0; i-- {
+		if out[i] < '0' || out[i] > '9' {
+			continue
+		}
+		if keep > 0 {
+			keep--
+			continue
+		}
+		out[i] = '*'
+	}
+	return string(out)
+}
+
 func (r *Redactor) Detokenize(ctx context.Context, text string, tokens []string) (string, error) {
 	detokenizedText := text
 	for _, token := range tokens {
@@ -97,3 +177,17 @@ func (r *Redactor) Detokenize(ctx context.Context, text string, tokens []string)
 	}
 	return detokenizedText, nil
 }
+
+// TextDetector is the detection step RedactText runs before redacting.
+type TextDetector interface {
+	Detect(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error)
+}
+
+// RedactText detects PII in req.Text with d and redacts it according to req.
+func (r *Redactor) RedactText(ctx context.Context, d TextDetector, req model.RedactionRequest) (model.RedactionResponse, error) {
+	detections, err := d.Detect(ctx, req.DetectionRequest)
+	if err != nil {
+		return model.RedactionResponse{}, err
+	}
+	return r.Redact(ctx, req.Text, detections, req.Mode, req.TTL)
+}
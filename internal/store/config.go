@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ConfigStore persists per-tenant configuration documents, such as custom patterns,
+// as JSON. Each document is addressed by tenant and kind.
+type ConfigStore interface {
+	// Get decodes the document into v and reports whether it exists.
+	Get(ctx context.Context, tenant, kind string, v any) (bool, error)
+	Put(ctx context.Context, tenant, kind string, v any) error
+	Delete(ctx context.Context, tenant, kind string) error
+	// Tenants lists the tenants that have a document of the given kind.
+	Tenants(ctx context.Context, kind string) ([]string, error)
+}
+
+// NewConfigStore returns a FileConfigStore rooted at dir.
+func NewConfigStore(dir string) (ConfigStore, error) {
+	if dir == "" {
+		return nil, errors.New("config store directory must be set")
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create config store directory: %w", err)
+	}
+	return &FileConfigStore{dir: dir}, nil
+}
+
+// FileConfigStore keeps each document in <dir>/<tenant>/<kind>.json.
+// Writes go to a temporary file first, so readers never see a partial document.
+type FileConfigStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+func (s *FileConfigStore) path(tenant, kind string) string {
+	return filepath.Join(s.dir, tenant, kind+".json")
+}
+
+func (s *FileConfigStore) Get(ctx context.Context, tenant, kind string, v any) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, err := os.ReadFile(s.path(tenant, kind))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s config for tenant %s: %w", kind, tenant, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to decode %s config for tenant %s: %w", kind, tenant, err)
+	}
+	return true, nil
+}
+
+func (s *FileConfigStore) Put(ctx context.Context, tenant, kind string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s config: %w", kind, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.path(tenant, kind)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to store %s config for tenant %s: %w", kind, tenant, err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("failed to store %s config for tenant %s: %w", kind, tenant, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to store %s config for tenant %s: %w", kind, tenant, err)
+	}
+	return nil
+}
+
+func (s *FileConfigStore) Delete(ctx context.Context, tenant, kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(tenant, kind)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete %s config for tenant %s: %w", kind, tenant, err)
+	}
+	return nil
+}
+
+func (s *FileConfigStore) Tenants(ctx context.Context, kind string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*", kind+".json"))
+	if err != nil {
+		return nil, err
+	}
+	tenants := make([]string, 0, len(matches))
+	for _, m := range matches {
+		tenants = append(tenants, filepath.Base(filepath.Dir(m)))
+	}
+	sort.Strings(tenants)
+	return tenants, nil
+}
+
+// MemoryConfigStore keeps documents in memory, for tests. Its contents are lost on
+// restart and are not shared between instances.
+type MemoryConfigStore struct {
+	mu   sync.RWMutex
+	docs map[string]map[string][]byte // kind -> tenant -> JSON
+}
+
+func NewMemoryConfigStore() *MemoryConfigStore {
+	return &MemoryConfigStore{docs: make(map[string]map[string][]byte)}
+}
+
+func (s *MemoryConfigStore) Get(ctx context.Context, tenant, kind string, v any) (bool, error) {
+	s.mu.RLock()
+	data, ok := s.docs[kind][tenant]
+	s.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (s *MemoryConfigStore) Put(ctx context.Context, tenant, kind string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s config: %w", kind, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.docs[kind] == nil {
+		s.docs[kind] = make(map[string][]byte)
+	}
+	s.docs[kind][tenant] = data
+	return nil
+}
+
+func (s *MemoryConfigStore) Delete(ctx context.Context, tenant, kind string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.docs[kind], tenant)
+	return nil
+}
+
+func (s *MemoryConfigStore) Tenants(ctx context.Context, kind string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	tenants := make([]string, 0, len(s.docs[kind]))
+	for t := range s.docs[kind] {
+		tenants = append(tenants, t)
+	}
+	sort.Strings(tenants)
+	return tenants, nil
+}
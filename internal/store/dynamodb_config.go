@@ -0,0 +1,104 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoDBConfigStore keeps each document as JSON in an item keyed by tenant (partition
+// key) and kind (sort key), so every instance sees the same configuration.
+type DynamoDBConfigStore struct {
+	client    *dynamodb.Client
+	tableName string
+}
+
+func NewDynamoDBConfigStore(ctx context.Context, region, tableName string) (*DynamoDBConfigStore, error) {
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	return &DynamoDBConfigStore{
+		client:    dynamodb.NewFromConfig(cfg),
+		tableName: tableName,
+	}, nil
+}
+
+func (s *DynamoDBConfigStore) key(tenant, kind string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"tenant": &types.AttributeValueMemberS{Value: tenant},
+		"kind":   &types.AttributeValueMemberS{Value: kind},
+	}
+}
+
+func (s *DynamoDBConfigStore) Get(ctx context.Context, tenant, kind string, v any) (bool, error) {
+	result, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.tableName),
+		Key:            s.key(tenant, kind),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s config for tenant %s: %w", kind, tenant, err)
+	}
+	doc, ok := result.Item["document"].(*types.AttributeValueMemberS)
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(doc.Value), v); err != nil {
+		return false, fmt.Errorf("failed to decode %s config for tenant %s: %w", kind, tenant, err)
+	}
+	return true, nil
+}
+
+func (s *DynamoDBConfigStore) Put(ctx context.Context, tenant, kind string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s config: %w", kind, err)
+	}
+	item := s.key(tenant, kind)
+	item["document"] = &types.AttributeValueMemberS{Value: string(data)}
+	if _, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(s.tableName), Item: item}); err != nil {
+		return fmt.Errorf("failed to store %s config for tenant %s: %w", kind, tenant, err)
+	}
+	return nil
+}
+
+func (s *DynamoDBConfigStore) Delete(ctx context.Context, tenant, kind string) error {
+	if _, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(s.tableName), Key: s.key(tenant, kind)}); err != nil {
+		return fmt.Errorf("failed to delete %s config for tenant %s: %w", kind, tenant, err)
+	}
+	return nil
+}
+
+// Tenants scans the table. It runs once per kind at startup.
+func (s *DynamoDBConfigStore) Tenants(ctx context.Context, kind string) ([]string, error) {
+	paginator := dynamodb.NewScanPaginator(s.client, &dynamodb.ScanInput{
+		TableName:                 aws.String(s.tableName),
+		FilterExpression:          aws.String("#kind = :kind"),
+		ProjectionExpression:      aws.String("#tenant"),
+		ExpressionAttributeNames:  map[string]string{"#kind": "kind", "#tenant": "tenant"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":kind": &types.AttributeValueMemberS{Value: kind}},
+		ConsistentRead:            aws.Bool(true),
+	})
+	var tenants []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tenants with %s config: %w", kind, err)
+		}
+		for _, item := range page.Items {
+			if t, ok := item["tenant"].(*types.AttributeValueMemberS); ok {
+				tenants = append(tenants, t.Value)
+			}
+		}
+	}
+	sort.Strings(tenants)
+	return tenants, nil
+}
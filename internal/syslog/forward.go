@@ -0,0 +1,106 @@
+package syslog
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const dialTimeout = 5 * time.Second
+
+// Forwarder delivers redacted messages downstream.
+type Forwarder interface {
+	Forward(msg string) error
+	Close() error
+}
+
+// NewForwarder creates a forwarder for target, which is one of
+// udp://host:port, tcp://host:port or file:///path/to/file.
+func NewForwarder(target string) (Forwarder, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid syslog forward target %q: %w", target, err)
+	}
+	switch u.Scheme {
+	case "udp", "tcp":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid syslog forward target %q: missing host", target)
+		}
+		return &netForwarder{network: u.Scheme, addr: u.Host}, nil
+	case "file":
+		f, err := os.OpenFile(u.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open syslog forward file: %w", err)
+		}
+		return &writerForwarder{w: f}, nil
+	default:
+		return nil, fmt.Errorf("unsupported syslog forward scheme %q", u.Scheme)
+	}
+}
+
+// netForwarder sends one datagram per message over UDP, or octet-counted frames
+// (RFC 6587) over TCP. The connection is re-established after a write failure.
+type netForwarder struct {
+	network string
+	addr    string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (f *netForwarder) Forward(msg string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.conn == nil {
+		conn, err := net.DialTimeout(f.network, f.addr, dialTimeout)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog target: %w", err)
+		}
+		f.conn = conn
+	}
+
+	frame := msg
+	if f.network == "tcp" {
+		frame = strconv.Itoa(len(msg)) + " " + msg
+	}
+	if _, err := io.WriteString(f.conn, frame); err != nil {
+		f.conn.Close()
+		f.conn = nil
+		return fmt.Errorf("failed to forward syslog message: %w", err)
+	}
+	return nil
+}
+
+func (f *netForwarder) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.conn == nil {
+		return nil
+	}
+	err := f.conn.Close()
+	f.conn = nil
+	return err
+}
+
+// writerForwarder appends newline-terminated messages to a file.
+type writerForwarder struct {
+	mu sync.Mutex
+	w  io.WriteCloser
+}
+
+func (f *writerForwarder) Forward(msg string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err := io.WriteString(f.w, msg+"\n")
+	return err
+}
+
+func (f *writerForwarder) Close() error {
+	return f.w.Close()
+}
@@ -0,0 +1,223 @@
+package syslog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format identifies the syslog wire format of a message.
+type Format string
+
+const (
+	RFC5424 Format = "rfc5424"
+	RFC3164 Format = "rfc3164"
+	Unknown Format = "unknown"
+)
+
+// Message is a parsed syslog message. Header holds everything before the free-form
+// MSG part verbatim, or for RFC 5424 everything before the STRUCTURED-DATA, so a
+// message can be re-serialised unchanged apart from its text and parameter values.
+type Message struct {
+	Format   Format
+	Priority int
+	Hostname string
+	AppName  string
+	Header   string
+	// StructuredData holds the RFC 5424 SD-ELEMENTs, nil for the NILVALUE.
+	StructuredData []SDElement
+	Text           string
+}
+
+// SDElement is an RFC 5424 SD-ELEMENT, such as [origin ip="10.0.0.1"].
+type SDElement struct {
+	ID     string
+	Params []SDParam
+}
+
+// SDParam is an SD-PARAM. Value is unescaped; raw keeps the value as received, so an
+// unchanged value is written back byte for byte.
+type SDParam struct {
+	Name  string
+	Value string
+	raw   string
+}
+
+// String re-serialises the message.
+func (m Message) String() string {
+	if m.Format != RFC5424 {
+		return m.Header + m.Text
+	}
+	var b strings.Builder
+	b.WriteString(m.Header)
+	if len(m.StructuredData) == 0 {
+		b.WriteByte('-')
+	}
+	for _, e := range m.StructuredData {
+		b.WriteString("[" + e.ID)
+		for _, p := range e.Params {
+			raw := p.raw
+			if p.Value != unescapeParamValue(raw) {
+				raw = escapeParamValue(p.Value)
+			}
+			b.WriteString(" " + p.Name + `="` + raw + `"`)
+		}
+		b.WriteByte(']')
+	}
+	if m.Text != "" {
+		b.WriteString(" " + m.Text)
+	}
+	return b.String()
+}
+
+var (
+	priPattern     = regexp.MustCompile(`^<(\d{1,3})>`)
+	rfc3164Pattern = regexp.MustCompile(`^<\d{1,3}>([A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d) (\S+) ([^:\[\s]+)(?:\[[^\]]*\])?: ?`)
+)
+
+// Parse splits a raw syslog message into its header and text. It never fails: input
+// that does not look like syslog is returned with Format Unknown and an empty header.
+func Parse(raw string) Message {
+	raw = strings.TrimRight(raw, "\r\n\x00")
+
+	m := priPattern.FindStringSubmatch(raw)
+	if m == nil {
+		return Message{Format: Unknown, Text: raw}
+	}
+	pri, _ := strconv.Atoi(m[1])
+	rest := raw[len(m[0]):]
+
+	if strings.HasPrefix(rest, "1 ") {
+		if msg, ok := parse5424(raw, len(m[0])); ok {
+			msg.Priority = pri
+			return msg
+		}
+	}
+	if h := rfc3164Pattern.FindStringSubmatch(raw); h != nil {
+		header := h[0]
+		return Message{
+			Format:   RFC3164,
+			Priority: pri,
+			Hostname: h[2],
+			AppName:  h[3],
+			Header:   header,
+			Text:     raw[len(header):],
+		}
+	}
+	return Message{Format: RFC3164, Priority: pri, Header: m[0], Text: rest}
+}
+
+// parse5424 parses "VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP SD [SP MSG]"
+// starting at offset i, just past the PRI.
+func parse5424(raw string, i int) (Message, bool) {
+	var fields [6]string
+	for n := range fields {
+		end := strings.IndexByte(raw[i:], ' ')
+		if end <= 0 {
+			return Message{}, false
+		}
+		fields[n] = raw[i : i+end]
+		i += end + 1
+	}
+
+	header := raw[:i]
+	sd, sdEnd, ok := parseStructuredData(raw, i)
+	if !ok {
+		return Message{}, false
+	}
+	i = sdEnd
+	if i < len(raw) {
+		if raw[i] != ' ' {
+			return Message{}, false
+		}
+		i++
+	}
+	return Message{
+		Format:         RFC5424,
+		Hostname:       nilValue(fields[2]),
+		AppName:        nilValue(fields[3]),
+		Header:         header,
+		StructuredData: sd,
+		Text:           raw[i:],
+	}, true
+}
+
+// parseStructuredData parses the STRUCTURED-DATA field starting at i and returns its
+// elements and the offset just past it.
+func parseStructuredData(raw string, i int) ([]SDElement, int, bool) {
+	if i >= len(raw) {
+		return nil, 0, false
+	}
+	if raw[i] == '-' {
+		return nil, i + 1, true
+	}
+	var elements []SDElement
+	for i < len(raw) && raw[i] == '[' {
+		i++
+		end := strings.IndexAny(raw[i:], " ]")
+		if end <= 0 {
+			return nil, 0, false
+		}
+		e := SDElement{ID: raw[i : i+end]}
+		i += end
+		for i < len(raw) && raw[i] == ' ' {
+			i++
+			eq := strings.IndexByte(raw[i:], '=')
+			if eq <= 0 || i+eq+1 >= len(raw) || raw[i+eq+1] != '"' {
+				return nil, 0, false
+			}
+			name := raw[i : i+eq]
+			i += eq + 2
+			start := i
+			for ; i < len(raw) && raw[i] != '"'; i++ {
+				if raw[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(raw) {
+				return nil, 0, false
+			}
+			value := raw[start:i]
+			e.Params = append(e.Params, SDParam{Name: name, Value: unescapeParamValue(value), raw: value})
+			i++
+		}
+		if i >= len(raw) || raw[i] != ']' {
+			return nil, 0, false
+		}
+		i++
+		elements = append(elements, e)
+	}
+	if len(elements) == 0 {
+		return nil, 0, false
+	}
+	return elements, i, true
+}
+
+// unescapeParamValue undoes the escaping of '"', '\' and ']' in a PARAM-VALUE. Any
+// other backslash is taken literally, as RFC 5424 requires.
+func unescapeParamValue(v string) string {
+	if !strings.Contains(v, `\`) {
+		return v
+	}
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '\\' && i+1 < len(v) && strings.IndexByte(`"\]`, v[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String()
+}
+
+var paramValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+func escapeParamValue(v string) string {
+	return paramValueEscaper.Replace(v)
+}
+
+func nilValue(v string) string {
+	if v == "-" {
+		return ""
+	}
+	return v
+}
@@ -0,0 +1,116 @@
+package syslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+// Policy controls how messages from a source are handled.
+type Policy struct {
+	Mode                model.RedactionMode `json:"mode,omitempty"`
+	Locale              string              `json:"locale,omitempty"`
+	EntityTypes         []string            `json:"entity_types,omitempty"`
+	ConfidenceThreshold float64             `json:"confidence_threshold,omitempty"`
+	// Drop discards messages from the source instead of forwarding them.
+	Drop bool `json:"drop,omitempty"`
+	// Passthrough forwards messages from the source without redaction.
+	Passthrough bool `json:"passthrough,omitempty"`
+}
+
+// SourcePolicy applies a Policy to messages whose sender address falls in Source
+// (an IP or CIDR) or, with Policies.TrustHostnames, whose syslog HOSTNAME equals Source.
+type SourcePolicy struct {
+	Source string `json:"source"`
+	Policy
+	network *net.IPNet
+}
+
+// Policies is the policy set of a receiver. The first matching source wins.
+type Policies struct {
+	Default Policy         `json:"default"`
+	Sources []SourcePolicy `json:"sources,omitempty"`
+	// TrustHostnames allows sources to be matched by HOSTNAME. The sender writes that
+	// field, so only set it when every sender is trusted not to claim another's name.
+	TrustHostnames bool `json:"trust_hostnames,omitempty"`
+}
+
+// LoadPolicies reads a JSON policy file.
+func LoadPolicies(path string) (Policies, error) {
+	var p Policies
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, fmt.Errorf("failed to read syslog policy file: %w", err)
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("failed to parse syslog policy file: %w", err)
+	}
+	return p, p.compile()
+}
+
+func (p *Policies) compile() error {
+	for i := range p.Sources {
+		src := &p.Sources[i]
+		switch {
+		case strings.Contains(src.Source, "/"):
+			_, network, err := net.ParseCIDR(src.Source)
+			if err != nil {
+				return fmt.Errorf("invalid syslog policy source %q: %w", src.Source, err)
+			}
+			src.network = network
+		case net.ParseIP(src.Source) != nil:
+			ip := net.ParseIP(src.Source)
+			bits := 8 * len(ip.To4())
+			if bits == 0 {
+				bits = 128
+			}
+			src.network = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		case !p.TrustHostnames:
+			return fmt.Errorf("syslog policy source %q is a hostname, which needs trust_hostnames", src.Source)
+		}
+	}
+	return nil
+}
+
+// For returns the policy for a message received from addr.
+func (p *Policies) For(addr net.Addr, msg Message) Policy {
+	ip := addrIP(addr)
+	for _, src := range p.Sources {
+		if src.network != nil {
+			if ip != nil && src.network.Contains(ip) {
+				return src.Policy
+			}
+			continue
+		}
+		if p.TrustHostnames && msg.Hostname != "" && strings.EqualFold(src.Source, msg.Hostname) {
+			return src.Policy
+		}
+	}
+	return p.Default
+}
+
+func (p Policy) request(text string) model.RedactionRequest {
+	return model.RedactionRequest{
+		DetectionRequest: model.DetectionRequest{
+			Text:                text,
+			Locale:              p.Locale,
+			EntityTypes:         p.EntityTypes,
+			ConfidenceThreshold: p.ConfidenceThreshold,
+		},
+		Mode: p.Mode,
+	}
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP
+	case *net.TCPAddr:
+		return a.IP
+	}
+	return nil
+}
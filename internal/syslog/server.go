@@ -0,0 +1,314 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	maxMessageBytes = 64 * 1024
+	// maxFrameLengthDigits bounds the octet count read before a frame's space.
+	maxFrameLengthDigits = 10
+)
+
+// Config configures a syslog receiver. At least one of UDPAddr and TCPAddr must be set.
+type Config struct {
+	UDPAddr   string
+	TCPAddr   string
+	QueueSize int
+	Workers   int
+	Policies  Policies
+}
+
+// Stats are the receiver's running counters.
+type Stats struct {
+	Received  uint64
+	Forwarded uint64
+	Dropped   uint64 // queue full (UDP only) or dropped by policy
+	Failed    uint64 // redaction or forwarding errors
+}
+
+type envelope struct {
+	addr net.Addr
+	raw  string
+}
+
+// Server receives syslog over UDP and TCP, redacts each message and forwards it.
+//
+// Messages are handed to a bounded queue drained by a fixed worker pool. When the
+// queue is full, TCP readers block, which pushes back on senders through the TCP
+// window; UDP has no flow control, so datagrams are dropped and counted instead.
+type Server struct {
+	cfg       Config
+	detector  redactor.TextDetector
+	redactor  *redactor.Redactor
+	forwarder Forwarder
+
+	queue   chan envelope
+	udp     net.PacketConn
+	tcp     net.Listener
+	conns   sync.Map
+	readers sync.WaitGroup
+	workers sync.WaitGroup
+	closed  atomic.Bool
+
+	received, forwarded, dropped, failed atomic.Uint64
+}
+
+func NewServer(cfg Config, detector redactor.TextDetector, redactor *redactor.Redactor, forwarder Forwarder) (*Server, error) {
+	if cfg.UDPAddr == "" && cfg.TCPAddr == "" {
+		return nil, errors.New("syslog receiver needs a UDP or TCP address")
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if err := cfg.Policies.compile(); err != nil {
+		return nil, err
+	}
+	return &Server{
+		cfg:       cfg,
+		detector:  detector,
+		redactor:  redactor,
+		forwarder: forwarder,
+		queue:     make(chan envelope, cfg.QueueSize),
+	}, nil
+}
+
+// Start binds the listeners and starts the workers. It returns once the server is ready.
+func (s *Server) Start(ctx context.Context) error {
+	if s.cfg.UDPAddr != "" {
+		conn, err := net.ListenPacket("udp", s.cfg.UDPAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on syslog UDP address: %w", err)
+		}
+		s.udp = conn
+		s.readers.Add(1)
+		go s.serveUDP()
+	}
+	if s.cfg.TCPAddr != "" {
+		ln, err := net.Listen("tcp", s.cfg.TCPAddr)
+		if err != nil {
+			s.Close()
+			return fmt.Errorf("failed to listen on syslog TCP address: %w", err)
+		}
+		s.tcp = ln
+		s.readers.Add(1)
+		go s.serveTCP()
+	}
+	for i := 0; i < s.cfg.Workers; i++ {
+		s.workers.Add(1)
+		go s.work(ctx)
+	}
+	return nil
+}
+
+// UDPAddr returns the bound UDP address, or nil.
+func (s *Server) UDPAddr() net.Addr {
+	if s.udp == nil {
+		return nil
+	}
+	return s.udp.LocalAddr()
+}
+
+// TCPAddr returns the bound TCP address, or nil.
+func (s *Server) TCPAddr() net.Addr {
+	if s.tcp == nil {
+		return nil
+	}
+	return s.tcp.Addr()
+}
+
+// Stats returns a snapshot of the receiver's counters.
+func (s *Server) Stats() Stats {
+	return Stats{
+		Received:  s.received.Load(),
+		Forwarded: s.forwarded.Load(),
+		Dropped:   s.dropped.Load(),
+		Failed:    s.failed.Load(),
+	}
+}
+
+// Close stops the listeners, drains the queue and closes the forwarder.
+func (s *Server) Close() error {
+	if !s.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	if s.udp != nil {
+		s.udp.Close()
+	}
+	if s.tcp != nil {
+		s.tcp.Close()
+	}
+	s.conns.Range(func(key, _ any) bool {
+		key.(net.Conn).Close()
+		return true
+	})
+	s.readers.Wait()
+	close(s.queue)
+	s.workers.Wait()
+	return s.forwarder.Close()
+}
+
+func (s *Server) serveUDP() {
+	defer s.readers.Done()
+	buf := make([]byte, maxMessageBytes)
+	for {
+		n, addr, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			if !s.closed.Load() {
+				log.Error().Err(err).Msg("Syslog UDP read failed")
+			}
+			return
+		}
+		s.received.Add(1)
+		select {
+		case s.queue <- envelope{addr: addr, raw: string(buf[:n])}:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+func (s *Server) serveTCP() {
+	defer s.readers.Done()
+	for {
+		conn, err := s.tcp.Accept()
+		if err != nil {
+			if !s.closed.Load() {
+				log.Error().Err(err).Msg("Syslog TCP accept failed")
+			}
+			return
+		}
+		s.readers.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+// serveConn reads octet-counted (RFC 6587 3.4.1) or newline-delimited frames.
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.readers.Done()
+	s.conns.Store(conn, struct{}{})
+	defer s.conns.Delete(conn)
+	defer conn.Close()
+	if s.closed.Load() {
+		return
+	}
+
+	r := bufio.NewReaderSize(conn, maxMessageBytes)
+	for {
+		raw, err := readFrame(r)
+		if err != nil {
+			if err != io.EOF && !s.closed.Load() {
+				log.Warn().Err(err).Str("remote", conn.RemoteAddr().String()).Msg("Syslog TCP connection closed")
+			}
+			return
+		}
+		if raw == "" {
+			continue
+		}
+		s.received.Add(1)
+		// Blocking here is the backpressure: we stop reading until workers catch up.
+		s.queue <- envelope{addr: conn.RemoteAddr(), raw: raw}
+	}
+}
+
+func readFrame(r *bufio.Reader) (string, error) {
+	first, err := r.Peek(1)
+	if err != nil {
+		return "", err
+	}
+	if first[0] < '1' || first[0] > '9' {
+		line, err := r.ReadString('\n')
+		if err == io.EOF && line != "" {
+			return strings.TrimRight(line, "\r\n"), nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	var lenStr []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if c == ' ' {
+			break
+		}
+		if lenStr = append(lenStr, c); len(lenStr) > maxFrameLengthDigits {
+			return "", fmt.Errorf("syslog frame length exceeds %d digits", maxFrameLengthDigits)
+		}
+	}
+	n, err := strconv.Atoi(string(lenStr))
+	if err != nil || n > maxMessageBytes {
+		return "", fmt.Errorf("invalid syslog frame length %q", lenStr)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func (s *Server) work(ctx context.Context) {
+	defer s.workers.Done()
+	for env := range s.queue {
+		if err := s.handle(ctx, env); err != nil {
+			s.failed.Add(1)
+			log.Error().Err(err).Msg("Syslog message not forwarded")
+		}
+	}
+}
+
+func (s *Server) handle(ctx context.Context, env envelope) error {
+	msg := Parse(env.raw)
+	policy := s.cfg.Policies.For(env.addr, msg)
+	if policy.Drop {
+		s.dropped.Add(1)
+		return nil
+	}
+
+	if !policy.Passthrough && strings.TrimSpace(msg.Text) != "" {
+		res, err := s.redactor.RedactText(ctx, s.detector, policy.request(msg.Text))
+		if err != nil {
+			// Never forward a message we could not scrub.
+			return fmt.Errorf("redaction failed: %w", err)
+		}
+		msg.Text = res.RedactedText
+	}
+	if !policy.Passthrough {
+		// SD-PARAM values, such as [origin user="jane@acme.com"], are free text too.
+		for _, e := range msg.StructuredData {
+			for i := range e.Params {
+				p := &e.Params[i]
+				if strings.TrimSpace(p.Value) == "" {
+					continue
+				}
+				res, err := s.redactor.RedactText(ctx, s.detector, policy.request(p.Value))
+				if err != nil {
+					return fmt.Errorf("redaction failed: %w", err)
+				}
+				p.Value = res.RedactedText
+			}
+		}
+	}
+
+	if err := s.forwarder.Forward(msg.String()); err != nil {
+		return err
+	}
+	s.forwarded.Add(1)
+	return nil
+}
@@ -0,0 +1,32 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+)
+
+// Default is the tenant used when a request does not name one.
+const Default = "default"
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type contextKey struct{}
+
+// WithID returns a copy of ctx carrying the tenant ID.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ID carried by ctx, or Default.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(contextKey{}).(string); ok && id != "" {
+		return id
+	}
+	return Default
+}
+
+// ValidID reports whether id is a well-formed tenant ID. IDs are used as storage
+// keys, so they are restricted to letters, digits, '-' and '_'.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
@@ -0,0 +1,183 @@
+package tenantconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/store"
+)
+
+const patternsKind = "patterns"
+
+const maxPatternsPerTenant = 100
+
+var (
+	ErrPatternExists   = errors.New("pattern already exists")
+	ErrPatternNotFound = errors.New("pattern not found")
+	ErrTooManyPatterns = fmt.Errorf("a tenant can have at most %d custom patterns", maxPatternsPerTenant)
+)
+
+// PatternService manages tenants' custom regex patterns. Changes are persisted to the
+// config store and pushed to the RegexDetector immediately, without a restart.
+type PatternService struct {
+	store store.ConfigStore
+	regex *detector.RegexDetector
+	mu    sync.Mutex
+}
+
+func NewPatternService(store store.ConfigStore, regex *detector.RegexDetector) *PatternService {
+	return &PatternService{store: store, regex: regex}
+}
+
+// Load pushes every tenant's stored patterns to the detector. Call it once at startup.
+func (s *PatternService) Load(ctx context.Context) error {
+	tenants, err := s.store.Tenants(ctx, patternsKind)
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		patterns, err := s.List(ctx, t)
+		if err != nil {
+			return err
+		}
+		if err := s.apply(t, patterns); err != nil {
+			return fmt.Errorf("tenant %s: %w", t, err)
+		}
+	}
+	return nil
+}
+
+// Refresh re-reads a tenant's patterns from the store, picking up changes made by
+// other instances.
+func (s *PatternService) Refresh(ctx context.Context, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	patterns, err := s.List(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return s.apply(tenantID, patterns)
+}
+
+// List returns a tenant's patterns sorted by name.
+func (s *PatternService) List(ctx context.Context, tenantID string) ([]model.CustomPattern, error) {
+	var patterns []model.CustomPattern
+	if _, err := s.store.Get(ctx, tenantID, patternsKind, &patterns); err != nil {
+		return nil, err
+	}
+	sort.Slice(patterns, func(i, j int) bool { return patterns[i].Name < patterns[j].Name })
+	return patterns, nil
+}
+
+// Create adds a pattern. It fails with ErrPatternExists if the name is taken.
+func (s *PatternService) Create(ctx context.Context, tenantID string, p model.CustomPattern) error {
+	return s.modify(ctx, tenantID, func(patterns []model.CustomPattern) ([]model.CustomPattern, error) {
+		if indexOf(patterns, p.Name) >= 0 {
+			return nil, ErrPatternExists
+		}
+		if len(patterns) >= maxPatternsPerTenant {
+			return nil, ErrTooManyPatterns
+		}
+		return append(patterns, p), nil
+	})
+}
+
+// Update replaces the pattern called name. It fails with ErrPatternNotFound if there is none.
+func (s *PatternService) Update(ctx context.Context, tenantID, name string, p model.CustomPattern) error {
+	return s.modify(ctx, tenantID, func(patterns []model.CustomPattern) ([]model.CustomPattern, error) {
+		i := indexOf(patterns, name)
+		if i < 0 {
+			return nil, ErrPatternNotFound
+		}
+		if p.Name != name && indexOf(patterns, p.Name) >= 0 {
+			return nil, ErrPatternExists
+		}
+		patterns[i] = p
+		return patterns, nil
+	})
+}
+
+// Delete removes the pattern called name.
+func (s *PatternService) Delete(ctx context.Context, tenantID, name string) error {
+	return s.modify(ctx, tenantID, func(patterns []model.CustomPattern) ([]model.CustomPattern, error) {
+		i := indexOf(patterns, name)
+		if i < 0 {
+			return nil, ErrPatternNotFound
+		}
+		return append(patterns[:i], patterns[i+1:]...), nil
+	})
+}
+
+// Test compiles p and returns its matches in text, without saving it.
+func (s *PatternService) Test(p model.CustomPattern, text string) ([]model.Detection, error) {
+	compiled, err := detector.CompilePattern(p)
+	if err != nil {
+		return nil, err
+	}
+	return detector.MatchPatterns(text, []detector.RegexPattern{compiled}), nil
+}
+
+func (s *PatternService) modify(ctx context.Context, tenantID string, fn func([]model.CustomPattern) ([]model.CustomPattern, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	patterns, err := s.List(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	patterns, err = fn(patterns)
+	if err != nil {
+		return err
+	}
+	compiled, err := compileAll(patterns)
+	if err != nil {
+		return err
+	}
+
+	if len(patterns) == 0 {
+		err = s.store.Delete(ctx, tenantID, patternsKind)
+	} else {
+		err = s.store.Put(ctx, tenantID, patternsKind, patterns)
+	}
+	if err != nil {
+		return err
+	}
+	s.regex.SetTenantPatterns(tenantID, compiled)
+	return nil
+}
+
+func (s *PatternService) apply(tenantID string, patterns []model.CustomPattern) error {
+	compiled, err := compileAll(patterns)
+	if err != nil {
+		return err
+	}
+	s.regex.SetTenantPatterns(tenantID, compiled)
+	return nil
+}
+
+func compileAll(patterns []model.CustomPattern) ([]detector.RegexPattern, error) {
+	compiled := make([]detector.RegexPattern, 0, len(patterns))
+	for _, p := range patterns {
+		c, err := detector.CompilePattern(p)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", p.Name, err)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+func indexOf(patterns []model.CustomPattern, name string) int {
+	for i, p := range patterns {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
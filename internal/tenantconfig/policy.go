@@ -0,0 +1,104 @@
+package tenantconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/store"
+)
+
+const policyKind = "policy"
+
+var ErrInvalidPolicy = errors.New("invalid policy")
+
+// PolicyService manages tenants' detection policies. Changes are persisted to the
+// config store and pushed to the Pipeline immediately.
+type PolicyService struct {
+	store    store.ConfigStore
+	pipeline *detector.Pipeline
+	mu       sync.Mutex
+}
+
+func NewPolicyService(store store.ConfigStore, pipeline *detector.Pipeline) *PolicyService {
+	return &PolicyService{store: store, pipeline: pipeline}
+}
+
+// Load pushes every tenant's stored policy to the pipeline. Call it once at startup.
+func (s *PolicyService) Load(ctx context.Context) error {
+	tenants, err := s.store.Tenants(ctx, policyKind)
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		policy, err := s.Get(ctx, t)
+		if err != nil {
+			return err
+		}
+		if err := validatePolicy(policy); err != nil {
+			return fmt.Errorf("tenant %s: %w", t, err)
+		}
+		s.pipeline.SetTenantPolicy(t, policy)
+	}
+	return nil
+}
+
+// Refresh re-reads a tenant's policy from the store, picking up changes made by
+// other instances.
+func (s *PolicyService) Refresh(ctx context.Context, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	policy, err := s.Get(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := validatePolicy(policy); err != nil {
+		return fmt.Errorf("tenant %s: %w", tenantID, err)
+	}
+	s.pipeline.SetTenantPolicy(tenantID, policy)
+	return nil
+}
+
+func (s *PolicyService) Get(ctx context.Context, tenantID string) (model.DetectionPolicy, error) {
+	var policy model.DetectionPolicy
+	_, err := s.store.Get(ctx, tenantID, policyKind, &policy)
+	return policy, err
+}
+
+// Put replaces a tenant's policy. A zero policy restores the defaults.
+func (s *PolicyService) Put(ctx context.Context, tenantID string, policy model.DetectionPolicy) error {
+	if err := validatePolicy(policy); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	if policy.ConfidenceThreshold == 0 && len(policy.EntityThresholds) == 0 {
+		err = s.store.Delete(ctx, tenantID, policyKind)
+	} else {
+		err = s.store.Put(ctx, tenantID, policyKind, policy)
+	}
+	if err != nil {
+		return err
+	}
+	s.pipeline.SetTenantPolicy(tenantID, policy)
+	return nil
+}
+
+func validatePolicy(policy model.DetectionPolicy) error {
+	if policy.ConfidenceThreshold < 0 || policy.ConfidenceThreshold > 1 {
+		return fmt.Errorf("%w: confidence_threshold must be between 0 and 1", ErrInvalidPolicy)
+	}
+	for entity, threshold := range policy.EntityThresholds {
+		if threshold < 0 || threshold > 1 {
+			return fmt.Errorf("%w: threshold for %s must be between 0 and 1", ErrInvalidPolicy, entity)
+		}
+	}
+	return nil
+}
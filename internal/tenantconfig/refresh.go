@@ -0,0 +1,65 @@
+package tenantconfig
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// refresher is implemented by the services whose tenant config can be re-read.
+type refresher interface {
+	Refresh(ctx context.Context, tenantID string) error
+}
+
+// Refresher keeps each instance's copy of tenant config in step with the config store.
+// Every instance loads the store at startup but only applies its own writes, so a
+// tenant's config is re-read once it is older than the interval. Refreshing on use
+// rather than on a timer also works on Lambda, where instances are frozen between
+// invocations.
+type Refresher struct {
+	services []refresher
+	interval time.Duration
+
+	mu      sync.Mutex
+	fetched map[string]time.Time
+}
+
+// NewRefresher re-reads a tenant's patterns, context rules and policy once they are
+// older than interval. An interval of zero disables refreshing.
+func NewRefresher(interval time.Duration, patterns *PatternService, rules *RuleService, policies *PolicyService) *Refresher {
+	return &Refresher{
+		services: []refresher{patterns, rules, policies},
+		interval: interval,
+		fetched:  make(map[string]time.Time),
+	}
+}
+
+// Refresh re-reads tenantID's config if it is stale. On failure the config already
+// loaded stays in use, and the next call tries again.
+func (r *Refresher) Refresh(ctx context.Context, tenantID string) error {
+	if r.interval <= 0 {
+		return nil
+	}
+	r.mu.Lock()
+	now := time.Now()
+	if now.Sub(r.fetched[tenantID]) < r.interval {
+		r.mu.Unlock()
+		return nil
+	}
+	// Claim the refresh, so concurrent requests keep using the current config.
+	r.fetched[tenantID] = now
+	r.mu.Unlock()
+
+	var errs []error
+	for _, s := range r.services {
+		errs = append(errs, s.Refresh(ctx, tenantID))
+	}
+	if err := errors.Join(errs...); err != nil {
+		r.mu.Lock()
+		delete(r.fetched, tenantID)
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package tenantconfig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/store"
+)
+
+const contextRulesKind = "context_rules"
+
+// RuleService manages tenants' context rule overrides. Changes are persisted to the
+// config store and pushed to the ContextAnalyzer immediately.
+type RuleService struct {
+	store    store.ConfigStore
+	analyzer *detector.ContextAnalyzer
+	mu       sync.Mutex
+}
+
+func NewRuleService(store store.ConfigStore, analyzer *detector.ContextAnalyzer) *RuleService {
+	return &RuleService{store: store, analyzer: analyzer}
+}
+
+// Load pushes every tenant's stored overrides to the analyzer. Call it once at startup.
+func (s *RuleService) Load(ctx context.Context) error {
+	tenants, err := s.store.Tenants(ctx, contextRulesKind)
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		overrides, err := s.Overrides(ctx, t)
+		if err != nil {
+			return err
+		}
+		if err := s.analyzer.SetTenantRules(t, overrides); err != nil {
+			return fmt.Errorf("tenant %s: %w", t, err)
+		}
+	}
+	return nil
+}
+
+// Refresh re-reads a tenant's overrides from the store, picking up changes made by
+// other instances.
+func (s *RuleService) Refresh(ctx context.Context, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	overrides, err := s.Overrides(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return s.analyzer.SetTenantRules(tenantID, overrides)
+}
+
+// Overrides returns the rules a tenant has overridden or added.
+func (s *RuleService) Overrides(ctx context.Context, tenantID string) ([]model.ContextRule, error) {
+	var overrides []model.ContextRule
+	if _, err := s.store.Get(ctx, tenantID, contextRulesKind, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// Effective returns the rules applied to a tenant's requests, in order.
+func (s *RuleService) Effective(tenantID string) []model.ContextRule {
+	return s.analyzer.Rules(tenantID)
+}
+
+// Put replaces a tenant's overrides. An empty list restores the built-in rules.
+func (s *RuleService) Put(ctx context.Context, tenantID string, overrides []model.ContextRule) error {
+	if err := detector.ValidateContextRules(overrides); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	if len(overrides) == 0 {
+		err = s.store.Delete(ctx, tenantID, contextRulesKind)
+	} else {
+		err = s.store.Put(ctx, tenantID, contextRulesKind, overrides)
+	}
+	if err != nil {
+		return err
+	}
+	return s.analyzer.SetTenantRules(tenantID, overrides)
+}
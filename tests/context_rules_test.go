@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/store"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+	"github.com/asoasis/pii-redaction-api/internal/tenantconfig"
+)
+
+func TestContextAnalyzer_Rules(t *testing.T) {
+	a := detector.NewContextAnalyzer()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		text     string
+		det      model.Detection
+		wantType string
+		wantConf float64 // 0 means suppressed
+	}{
+		{"Keyword boost", "My SSN is 123-45-6789", model.Detection{EntityType: "SSN", Start: 10, End: 21, Confidence: 0.90}, "SSN", 0.95},
+		{"Keyword must be a whole word", "Classnotes 123-45-6789", model.Detection{EntityType: "SSN", Start: 11, End: 22, Confidence: 0.90}, "SSN", 0.90},
+		{"Penalty", "Order number 123-45-6789", model.Detection{EntityType: "SSN", Start: 13, End: 24, Confidence: 0.95}, "SSN", 0.65},
+		{"Negative keyword blocks penalty", "Order SSN 123-45-6789", model.Detection{EntityType: "SSN", Start: 10, End: 21, Confidence: 0.90}, "SSN", 0.95},
+		{"Reclassify", "Deere & Company Inc reported", model.Detection{EntityType: "PERSON", Start: 0, End: 19, Confidence: 0.85}, "ORGANIZATION", 0.85},
+		{"Category rule", "Invoice 020 7946 0958", model.Detection{EntityType: "PHONE_UK", Start: 8, End: 21, Confidence: 0.85}, "PHONE_UK", 0.60},
+		{"Phone keywords are boosted by the detector only", "Call 415-555-2671", model.Detection{EntityType: "PHONE_US", Start: 5, End: 17, Confidence: 0.95}, "PHONE_US", 0.95},
+		{"BIC keyword boost", "BIC: DEUTDEFF", model.Detection{EntityType: "SWIFT_BIC", Start: 5, End: 13, Confidence: 0.50}, "SWIFT_BIC", 0.60},
+		{"BIC keyword must be a whole word", "Public notice: HOSPITAL closed", model.Detection{EntityType: "SWIFT_BIC", Start: 15, End: 23, Confidence: 0.50}, "SWIFT_BIC", 0.50},
+		{"Locale entity penalty", "Invoice ref 943 476 5919", model.Detection{EntityType: "UK_NHS_NUMBER", Start: 12, End: 24, Confidence: 0.75}, "UK_NHS_NUMBER", 0.45},
+		{"Suppress", "Sent from noreply@example.com", model.Detection{EntityType: "EMAIL", Start: 10, End: 29, Confidence: 0.99}, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := a.Refine(ctx, tt.text, []model.Detection{tt.det})
+			if tt.wantConf == 0 {
+				if len(got) != 0 {
+					t.Fatalf("expected detection to be suppressed, got %+v", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].EntityType != tt.wantType || !approx(got[0].Confidence, tt.wantConf) {
+				t.Errorf("expected %s at %.2f, got %+v", tt.wantType, tt.wantConf, got)
+			}
+		})
+	}
+}
+
+func TestDefaultContextRules_CoverBuiltinEntities(t *testing.T) {
+	entityTypes := []string{
+		"EMAIL", "SSN", "CREDIT_CARD", "IP_ADDRESS", "PHONE_US", "PERSON", "ORGANIZATION", "LOCATION", "DATE",
+		"PHONE_UK", "UK_NINO", "UK_NHS_NUMBER", "UK_PASSPORT", "UK_DRIVING_LICENCE", "UK_POSTCODE", "UK_SORT_CODE", "UK_BANK_ACCOUNT",
+		"IBAN", "SWIFT_BIC", "EU_VAT", "DE_TAX_ID", "FR_NIR", "ES_DNI", "ES_NIE", "IT_FISCAL_CODE", "NL_BSN",
+		"IN_AADHAAR", "IN_PAN", "IN_GSTIN", "IN_IFSC", "IN_UPI_ID", "PHONE_IN",
+		"CA_SIN", "CA_POSTAL_CODE", "AU_TFN", "AU_MEDICARE", "AU_ABN", "BR_CPF", "BR_CNPJ",
+		"CN_RESIDENT_ID", "JP_MY_NUMBER", "KR_RRN", "SG_NRIC",
+	}
+	rules := detector.DefaultContextRules()
+	for _, entityType := range entityTypes {
+		covered := false
+		for _, r := range rules {
+			if detector.EntityIsA(entityType, r.EntityType) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			t.Errorf("no built-in context rule applies to %s", entityType)
+		}
+	}
+}
+
+func TestContextAnalyzer_SentenceWindow(t *testing.T) {
+	a := detector.NewContextAnalyzer()
+	rules := []model.ContextRule{{
+		ID: "same-sentence", EntityType: "SSN", Keywords: []string{"tax id"},
+		Window: 1, Unit: "sentences", Action: model.BoostAction, Amount: 0.05,
+	}}
+	if err := a.SetRules(rules); err != nil {
+		t.Fatal(err)
+	}
+	det := model.Detection{EntityType: "SSN", Start: 36, End: 47, Confidence: 0.80}
+	text := "Your tax id was verified. Reference 123-45-6789"
+	if got := a.Refine(context.Background(), text, []model.Detection{det}); !approx(got[0].Confidence, 0.80) {
+		t.Errorf("keyword in previous sentence should not fire, got %+v", got)
+	}
+	text = "Your tax id is in record, 123-45-6789"
+	det.Start, det.End = 26, 37
+	if got := a.Refine(context.Background(), text, []model.Detection{det}); !approx(got[0].Confidence, 0.85) {
+		t.Errorf("keyword in same sentence should fire, got %+v", got)
+	}
+}
+
+func TestRuleService_TenantOverrides(t *testing.T) {
+	configStore, _ := store.NewConfigStore(t.TempDir())
+	a := detector.NewContextAnalyzer()
+	svc := tenantconfig.NewRuleService(configStore, a)
+	acme := tenant.WithID(context.Background(), "acme")
+	text := "Sent from noreply@example.com"
+	det := model.Detection{EntityType: "EMAIL", Start: 10, End: 29, Confidence: 0.99}
+
+	if err := svc.Put(acme, "acme", []model.ContextRule{{ID: "email-noreply-suppress", Disabled: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Refine(acme, text, []model.Detection{det}); len(got) != 1 {
+		t.Errorf("disabled rule still applied for tenant: %+v", got)
+	}
+	if got := a.Refine(context.Background(), text, []model.Detection{det}); len(got) != 0 {
+		t.Errorf("override leaked to default tenant: %+v", got)
+	}
+
+	if err := svc.Put(acme, "acme", []model.ContextRule{{ID: "bad", EntityType: "EMAIL", Action: "explode"}}); err == nil {
+		t.Error("expected invalid rule to be rejected")
+	}
+
+	// A fresh analyzer picks up the stored overrides at startup.
+	a2 := detector.NewContextAnalyzer()
+	if err := tenantconfig.NewRuleService(configStore, a2).Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := a2.Refine(acme, text, []model.Detection{det}); len(got) != 1 {
+		t.Errorf("stored override not loaded: %+v", got)
+	}
+}
+
+func approx(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
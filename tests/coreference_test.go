@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+func TestPipeline_Coreference(t *testing.T) {
+	text := "Jonathan Miller called today. Mr. Miller said Miller's car was stolen. Jon was upset. Jonathan Millerson was not."
+	p := detector.NewPipeline("en-US", false)
+	p.Registry().Register(stubDetector{name: "names", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		return []model.Detection{{EntityType: "PERSON", Text: "Jonathan Miller", Start: 0, End: 15, Confidence: 0.9}}, nil
+	}}, detector.DetectorOptions{})
+
+	detections, err := p.Detect(context.Background(), model.DetectionRequest{Text: text})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	for _, d := range detections {
+		if d.EntityID != "e1" {
+			t.Errorf("expected %q to be linked to e1, got %q", d.Text, d.EntityID)
+		}
+		if d.Start > 0 && d.DetectionMethod != "coreference" {
+			t.Errorf("expected %q to come from coreference, got %q", d.Text, d.DetectionMethod)
+		}
+		found = append(found, d.Text)
+	}
+	// The Jonathan of "Jonathan Millerson" is someone else.
+	want := []string{"Jonathan Miller", "Mr. Miller", "Miller", "Jon"}
+	if strings.Join(found, "|") != strings.Join(want, "|") {
+		t.Errorf("expected mentions %v, got %v", want, found)
+	}
+
+	// A bare nickname is propagated at a lower confidence than the surname.
+	other := "Alexandra Reid joined. Sandra from accounts and Alexandra Fox met Reid."
+	p2 := detector.NewPipeline("en-US", false)
+	p2.Registry().Register(stubDetector{name: "names", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		return []model.Detection{{EntityType: "PERSON", Text: "Alexandra Reid", Start: 0, End: 14, Confidence: 0.85}}, nil
+	}}, detector.DetectorOptions{})
+	detections, _ = p2.Detect(context.Background(), model.DetectionRequest{Text: other})
+	found = nil
+	for _, d := range detections {
+		found = append(found, d.Text)
+	}
+	if want := []string{"Alexandra Reid", "Sandra", "Reid"}; strings.Join(found, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected mentions %v, got %v", want, found)
+	}
+	if detections[1].Confidence >= detections[2].Confidence {
+		t.Errorf("expected the nickname below the surname, got %+v", detections)
+	}
+
+	// Seeds below the minimum confidence are not propagated.
+	p.SetCoreference(detector.Coreference{Enabled: true, MinConfidence: 0.95})
+	detections, _ = p.Detect(context.Background(), model.DetectionRequest{Text: text})
+	if len(detections) != 1 {
+		t.Errorf("expected only the seed, got %+v", detections)
+	}
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+func TestPipeline_Explain(t *testing.T) {
+	p := detector.NewPipeline("en-US", false)
+	text := "My SSN is 123-45-6789, ticket 666-12-3456"
+
+	res, err := p.Analyze(context.Background(), model.DetectionRequest{Text: text, Explain: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Detections) != 1 || res.Detections[0].Explanation == nil {
+		t.Fatalf("expected one explained SSN, got %+v", res.Detections)
+	}
+	e := res.Detections[0].Explanation
+	if e.Pattern != "en-US/SSN" || e.Validator != "ssn" || e.ValidatorResult != "passed" || e.BaseConfidence != 0.95 {
+		t.Errorf("unexpected pattern details: %+v", e)
+	}
+	if len(e.Adjustments) != 1 || e.Adjustments[0].RuleID != "ssn-keyword-boost" || e.Adjustments[0].Keyword != "ssn" {
+		t.Errorf("expected ssn-keyword-boost adjustment, got %+v", e.Adjustments)
+	}
+	if len(e.Decisions) != 1 || e.Decisions[0].Stage != "threshold" || e.Decisions[0].Outcome != "kept" {
+		t.Errorf("expected threshold decision, got %+v", e.Decisions)
+	}
+
+	if len(res.Dropped) != 1 || res.Dropped[0].Text != "666-12-3456" {
+		t.Fatalf("expected the invalid SSN to be dropped, got %+v", res.Dropped)
+	}
+	if d := res.Dropped[0].Explanation; d.ValidatorResult != "failed" || d.Decisions[0].Stage != "validator" {
+		t.Errorf("expected validator failure, got %+v", d)
+	}
+
+	// Without explain, nothing extra is returned.
+	res, _ = p.Analyze(context.Background(), model.DetectionRequest{Text: text})
+	if res.Detections[0].Explanation != nil || res.Dropped != nil {
+		t.Errorf("explanations returned without explain: %+v", res)
+	}
+}
+
+func TestPipeline_ExplainOverlap(t *testing.T) {
+	p := detector.NewPipeline("en-US", false)
+	p.Registry().Register(stubDetector{name: "stub", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		return []model.Detection{
+			{EntityType: "PERSON", Start: 0, End: 5, Confidence: 0.97},
+			{EntityType: "EMAIL", Start: 0, End: 11, Confidence: 0.95},
+		}, nil
+	}}, detector.DetectorOptions{})
+
+	res, err := p.Analyze(context.Background(), model.DetectionRequest{Text: "alpha bravo", Explain: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Detections) != 1 || len(res.Dropped) != 1 || res.Dropped[0].EntityType != "EMAIL" {
+		t.Fatalf("expected EMAIL to lose to PERSON, got %+v / %+v", res.Detections, res.Dropped)
+	}
+	decisions := res.Dropped[0].Explanation.Decisions
+	last := decisions[len(decisions)-1]
+	if last.Stage != "overlap" || last.Outcome != "dropped" || last.Reason != "overlaps PERSON [0:5], preferred by highest_confidence" {
+		t.Errorf("unexpected overlap decision: %+v", last)
+	}
+	if res.Dropped[0].Explanation.Source != "stub" {
+		t.Errorf("expected the detector name as source, got %+v", res.Dropped[0].Explanation)
+	}
+}
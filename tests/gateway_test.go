@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/gateway"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+)
+
+// mockLLM answers every chat completion by echoing the last message, either as a single
+// completion or streamed in three-byte chunks.
+func mockLLM(t *testing.T, prompts *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream   bool `json:"stream"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("upstream got invalid body: %v", err)
+			return
+		}
+		prompt := req.Messages[len(req.Messages)-1].Content
+		*prompts = append(*prompts, prompt)
+		answer := "You said: " + prompt
+
+		if !req.Stream {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"id":      "chatcmpl-1",
+				"object":  "chat.completion",
+				"choices": []any{map[string]any{"index": 0, "message": map[string]any{"role": "assistant", "content": answer}, "finish_reason": "stop"}},
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < len(answer); i += 3 {
+			piece, _ := json.Marshal(answer[i:min(i+3, len(answer))])
+			fmt.Fprintf(w, "data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{\"content\":%s},\"finish_reason\":null}]}\n\n", piece)
+		}
+		fmt.Fprint(w, "data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+}
+
+func TestGateway_ChatCompletions(t *testing.T) {
+	var prompts []string
+	upstream := mockLLM(t, &prompts)
+	defer upstream.Close()
+
+	gw := gateway.New(gateway.Config{UpstreamURL: upstream.URL}, detector.NewPipeline("en-US", false), redactor.NewRedactor(nil))
+	const prompt = "Email jane@acme.com about SSN 123-45-6789, then email jane@acme.com again."
+
+	send := func(stream bool, conversationID string) (*httptest.ResponseRecorder, string) {
+		body := fmt.Sprintf(`{"model":"gpt-4o","stream":%t,"messages":[{"role":"user","content":%q}]}`, stream, prompt)
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set(gateway.ConversationHeader, conversationID)
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, req)
+		out, _ := io.ReadAll(rec.Body)
+		return rec, string(out)
+	}
+
+	rec, out := send(false, "")
+	conversationID := rec.Header().Get(gateway.ConversationHeader)
+	if conversationID == "" {
+		t.Fatal("expected a conversation ID in the response")
+	}
+	if want := "Email <EMAIL_1> about SSN <SSN_1>, then email <EMAIL_1> again."; prompts[0] != want {
+		t.Errorf("upstream prompt = %q, want %q", prompts[0], want)
+	}
+	if !strings.Contains(out, "You said: "+prompt) {
+		t.Errorf("completion not rehydrated: %s", out)
+	}
+
+	_, out = send(true, conversationID)
+	if prompts[1] != prompts[0] {
+		t.Errorf("pseudonyms not stable within a conversation: %q vs %q", prompts[1], prompts[0])
+	}
+	var streamed strings.Builder
+	for _, line := range strings.Split(out, "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("invalid stream chunk %q: %v", data, err)
+		}
+		for _, c := range chunk.Choices {
+			streamed.WriteString(c.Delta.Content)
+		}
+	}
+	if streamed.String() != "You said: "+prompt {
+		t.Errorf("streamed answer = %q", streamed.String())
+	}
+	if !strings.HasSuffix(out, "data: [DONE]\n\n") {
+		t.Errorf("stream did not end with [DONE]: %q", out)
+	}
+
+	// Conversation IDs the gateway did not issue, or issued to another tenant, would
+	// let the caller rehydrate someone else's placeholders.
+	if rec, _ := send(false, "guessed-id"); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected a made-up conversation ID to be rejected, got %d", rec.Code)
+	}
+	body := fmt.Sprintf(`{"model":"gpt-4o","messages":[{"role":"user","content":%q}]}`, "repeat <EMAIL_1>")
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req = req.WithContext(tenant.WithID(req.Context(), "acme"))
+	req.Header.Set(gateway.ConversationHeader, conversationID)
+	rec = httptest.NewRecorder()
+	gw.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest || strings.Contains(rec.Body.String(), "jane@acme.com") {
+		t.Errorf("expected another tenant's conversation ID to be rejected, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGateway_ToolCallArguments(t *testing.T) {
+	// The upstream answers with a call to send_message whose arguments quote the prompt.
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Stream   bool `json:"stream"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("upstream got invalid body: %v", err)
+			return
+		}
+		// Go escapes the placeholder's brackets; the non-streamed answer writes them as is.
+		arguments, _ := json.Marshal(map[string]string{"text": req.Messages[0].Content})
+
+		if !req.Stream {
+			literal := strings.NewReplacer(`\u003c`, "<", `\u003e`, ">").Replace(string(arguments))
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{
+				"id": "chatcmpl-1",
+				"choices": []any{map[string]any{"index": 0, "finish_reason": "tool_calls", "message": map[string]any{
+					"role": "assistant", "content": nil,
+					"tool_calls": []any{map[string]any{"id": "call_1", "type": "function", "function": map[string]any{"name": "send_message", "arguments": literal}}},
+				}}},
+			})
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"id\":\"call_1\",\"type\":\"function\",\"function\":{\"name\":\"send_message\",\"arguments\":\"\"}}]},\"finish_reason\":null}]}\n\n")
+		for i := 0; i < len(arguments); i += 3 {
+			piece, _ := json.Marshal(string(arguments[i:min(i+3, len(arguments))]))
+			fmt.Fprintf(w, "data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{\"tool_calls\":[{\"index\":0,\"function\":{\"arguments\":%s}}]},\"finish_reason\":null}]}\n\n", piece)
+		}
+		fmt.Fprint(w, "data: {\"id\":\"chatcmpl-1\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"tool_calls\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer upstream.Close()
+
+	// The name has quotes, which must be escaped inside the JSON arguments.
+	const prompt, name = `Tell Jo "JJ" Smith hello`, `Jo "JJ" Smith`
+	names := stubDetector{name: "names", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		i := strings.Index(req.Text, name)
+		if i < 0 {
+			return nil, nil
+		}
+		return []model.Detection{{EntityType: "PERSON", Text: name, Start: i, End: i + len(name), Confidence: 0.9}}, nil
+	}}
+	gw := gateway.New(gateway.Config{UpstreamURL: upstream.URL}, names, redactor.NewRedactor(nil))
+	send := func(stream bool) string {
+		body := fmt.Sprintf(`{"model":"gpt-4o","stream":%t,"messages":[{"role":"user","content":%q}]}`, stream, prompt)
+		rec := httptest.NewRecorder()
+		gw.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)))
+		return rec.Body.String()
+	}
+	expectArguments := func(arguments string) {
+		t.Helper()
+		var args map[string]string
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil || args["text"] != prompt {
+			t.Errorf("arguments not rehydrated: %s (%v)", arguments, err)
+		}
+	}
+
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []struct {
+					Function struct {
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal([]byte(send(false)), &completion); err != nil || len(completion.Choices) != 1 || len(completion.Choices[0].Message.ToolCalls) != 1 {
+		t.Fatalf("unexpected completion: %+v (%v)", completion, err)
+	}
+	expectArguments(completion.Choices[0].Message.ToolCalls[0].Function.Arguments)
+
+	var streamed strings.Builder
+	for _, line := range strings.Split(send(true), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					ToolCalls []struct {
+						Function struct {
+							Arguments string `json:"arguments"`
+						} `json:"function"`
+					} `json:"tool_calls"`
+				} `json:"delta"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("invalid stream chunk %q: %v", data, err)
+		}
+		for _, c := range chunk.Choices {
+			for _, call := range c.Delta.ToolCalls {
+				streamed.WriteString(call.Function.Arguments)
+			}
+		}
+	}
+	expectArguments(streamed.String())
+}
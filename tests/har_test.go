@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/handler"
+	"github.com/asoasis/pii-redaction-api/internal/har"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+)
+
+const sampleHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "WebInspector", "version": "537.36"},
+    "entries": [{
+      "startedDateTime": "2024-01-01T00:00:00.000Z",
+      "time": 12.5,
+      "request": {
+        "method": "POST",
+        "url": "https://api.example.com/users?email=jane%40acme.com&page=2",
+        "headers": [
+          {"name": "Authorization", "value": "Bearer secret-token"},
+          {"name": "Accept", "value": "application/json"}
+        ],
+        "cookies": [{"name": "session", "value": "abc123"}],
+        "queryString": [
+          {"name": "email", "value": "jane@acme.com"},
+          {"name": "page", "value": "2"}
+        ],
+        "postData": {"mimeType": "application/json", "text": "{\"ssn\":\"123-45-6789\",\"count\":3}"}
+      },
+      "response": {
+        "status": 200,
+        "headers": [{"name": "Set-Cookie", "value": "session=abc123"}],
+        "content": {"size": 30, "mimeType": "text/plain", "text": "call 555-867-5309"}
+      },
+      "timings": {"send": 1, "wait": 10, "receive": 1.5}
+    }]
+  }
+}`
+
+func newHARRedactor() *har.Redactor {
+	return har.NewRedactor(detector.NewPipeline("en-US", false), redactor.NewRedactor(nil))
+}
+
+func TestHAR_Redact(t *testing.T) {
+	opts := har.Options{Request: model.RedactionRequest{Mode: model.ReplaceMode}}
+	out, stats, err := newHARRedactor().RedactHAR(context.Background(), []byte(sampleHAR), opts)
+	if err != nil {
+		t.Fatalf("RedactHAR failed: %v", err)
+	}
+
+	for _, leaked := range []string{"secret-token", "abc123", "jane@acme.com", "jane%40acme.com", "123-45-6789", "555-867-5309"} {
+		if strings.Contains(string(out), leaked) {
+			t.Errorf("redacted HAR still contains %q", leaked)
+		}
+	}
+	for _, kept := range []string{"page=2", "WebInspector", `"receive": 1.5`, "application/json"} {
+		if !strings.Contains(string(out), kept) {
+			t.Errorf("redacted HAR lost %q", kept)
+		}
+	}
+
+	var doc struct {
+		Log struct {
+			Entries []struct {
+				Request struct {
+					URL      string `json:"url"`
+					PostData struct {
+						Text string `json:"text"`
+					} `json:"postData"`
+				} `json:"request"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("redacted HAR is not valid JSON: %v", err)
+	}
+	if !json.Valid([]byte(doc.Log.Entries[0].Request.PostData.Text)) {
+		t.Errorf("postData.text is no longer JSON: %s", doc.Log.Entries[0].Request.PostData.Text)
+	}
+	if stats.HeadersRedacted != 2 || stats.CookiesRedacted != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestHAR_RedactTranscript(t *testing.T) {
+	transcript := "GET /search?q=john@acme.com HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Cookie: session=abc123\r\n" +
+		"\r\n" +
+		"HTTP/1.1 200 OK\r\n" +
+		"Content-Type: application/json\r\n" +
+		"\r\n" +
+		`{"ssn": "123-45-6789"}` + "\r\n"
+
+	opts := har.Options{Request: model.RedactionRequest{Mode: model.ReplaceMode}}
+	out, _, err := newHARRedactor().RedactTranscript(context.Background(), transcript, opts)
+	if err != nil {
+		t.Fatalf("RedactTranscript failed: %v", err)
+	}
+	for _, leaked := range []string{"john@acme.com", "abc123", "123-45-6789"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("redacted transcript still contains %q:\n%s", leaked, out)
+		}
+	}
+	if !strings.Contains(out, "Host: example.com\r\n") || !strings.HasPrefix(out, "GET /search?q=") {
+		t.Errorf("transcript structure not preserved:\n%s", out)
+	}
+}
+
+func TestHAR_RedactTranscriptCookies(t *testing.T) {
+	transcript := "GET / HTTP/1.1\r\n" +
+		"Cookie: session=abc123; theme=dark\r\n" +
+		"\r\n" +
+		"HTTP/1.1 200 OK\r\n" +
+		"Set-Cookie: session=def456; Path=/; HttpOnly\r\n" +
+		"\r\n"
+
+	opts := har.Options{
+		HeaderDenylist: []string{"authorization"},
+		CookieDenylist: []string{"session"},
+		Request:        model.RedactionRequest{Mode: model.ReplaceMode},
+	}
+	out, stats, err := newHARRedactor().RedactTranscript(context.Background(), transcript, opts)
+	if err != nil {
+		t.Fatalf("RedactTranscript failed: %v", err)
+	}
+	for _, want := range []string{"Cookie: session=[REDACTED]; theme=dark\r\n", "Set-Cookie: session=[REDACTED]; Path=/; HttpOnly\r\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if stats.CookiesRedacted != 2 || stats.HeadersRedacted != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestHARHandler_Errors(t *testing.T) {
+	h := handler.NewHARHandler(har.NewRedactor(failOn("boom"), redactor.NewRedactor(nil)))
+	send := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/redact/har", strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := send(`{"har": {"entries": []}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a HAR without a log, got %d", rec.Code)
+	}
+	rec := send(`{"har": {"log": {"entries": [{"request": {"url": "https://example.com/boom"}}]}}}`)
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "detector failed") {
+		t.Errorf("expected a generic 500 for a redaction failure, got %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,30 @@
+package tests
+
+import "testing"
+
+func TestLocale_CN(t *testing.T) {
+	// No spaces separate the ID from the surrounding Chinese text.
+	expectEntities(t, "zh-CN", "身份证号码11010519491231002X号", map[string]string{"11010519491231002X": "CN_RESIDENT_ID"})
+	// A Latin label glued to the ID does not hide it.
+	expectEntities(t, "zh-CN", "证件ID11010519491231002X", map[string]string{"11010519491231002X": "CN_RESIDENT_ID"})
+	expectEntities(t, "zh-CN", "身份证号码110105194912310021", map[string]string{})
+	// Fullwidth digits are folded before matching.
+	expectEntities(t, "zh-CN", "身份证：１１０１０５１９４９１２３１００２Ｘ", map[string]string{"１１０１０５１９４９１２３１００２Ｘ": "CN_RESIDENT_ID"})
+}
+
+func TestLocale_JP(t *testing.T) {
+	expectEntities(t, "ja-JP", "マイナンバーは1234 5678 9018です", map[string]string{"1234 5678 9018": "JP_MY_NUMBER"})
+	expectEntities(t, "ja-JP", "マイナンバーは1234 5678 9017です", map[string]string{})
+}
+
+func TestLocale_KR(t *testing.T) {
+	expectEntities(t, "ko-KR", "주민등록번호:900101-1234567입니다", map[string]string{"900101-1234567": "KR_RRN"})
+	expectEntities(t, "ko-KR", "주민등록번호 901301-1234567", map[string]string{})
+}
+
+func TestLocale_SG(t *testing.T) {
+	expectEntities(t, "en-SG", "NRIC:S1234567D, not S1234567A", map[string]string{"S1234567D": "SG_NRIC"})
+	// Letters only count as a boundary when they are not Latin.
+	expectEntities(t, "en-SG", "身份证S1234567D", map[string]string{"S1234567D": "SG_NRIC"})
+	expectEntities(t, "en-SG", "XS1234567D", map[string]string{})
+}
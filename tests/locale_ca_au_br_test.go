@@ -0,0 +1,23 @@
+package tests
+
+import "testing"
+
+func TestLocale_CA(t *testing.T) {
+	for _, locale := range []string{"en-CA", "fr-CA"} {
+		expectEntities(t, locale, "SIN 130 692 544, not 130 692 545; K1A 0B1", map[string]string{
+			"130 692 544": "CA_SIN", "K1A 0B1": "CA_POSTAL_CODE",
+		})
+	}
+}
+
+func TestLocale_AU(t *testing.T) {
+	expectEntities(t, "en-AU", "TFN 123 456 782, not 123 456 783", map[string]string{"123 456 782": "AU_TFN"})
+	expectEntities(t, "en-AU", "Medicare 2123 45670 1, ABN 51 824 753 556", map[string]string{
+		"2123 45670 1": "AU_MEDICARE", "51 824 753 556": "AU_ABN",
+	})
+}
+
+func TestLocale_BR(t *testing.T) {
+	expectEntities(t, "pt-BR", "CPF 529.982.247-25, não 111.111.111-11 nem 529.982.247-26", map[string]string{"529.982.247-25": "BR_CPF"})
+	expectEntities(t, "pt-BR", "CNPJ 11.222.333/0001-81", map[string]string{"11.222.333/0001-81": "BR_CNPJ"})
+}
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+func TestLocale_EU(t *testing.T) {
+	// IBAN, BIC and VAT numbers are found in the European locales.
+	for _, locale := range []string{"en-GB", "de-DE"} {
+		expectEntities(t, locale, "IBAN DE89 3704 0044 0532 0130 00, swift DEUTDEFF", map[string]string{
+			"DE89 3704 0044 0532 0130 00": "IBAN", "DEUTDEFF": "SWIFT_BIC",
+		})
+	}
+	expectEntities(t, "en-GB", "Pay GB82 WEST 1234 5698 7654 32, not DE88 3704 0044 0532 0130 00", map[string]string{
+		"GB82 WEST 1234 5698 7654 32": "IBAN",
+	})
+	expectEntities(t, "de-DE", "VAT DE136695976, FR40303265045, ATU13585627, NL123456782B01; not DE136695977", map[string]string{
+		"DE136695976": "EU_VAT", "FR40303265045": "EU_VAT", "ATU13585627": "EU_VAT", "NL123456782B01": "EU_VAT",
+	})
+	expectEntities(t, "de-DE", "VAT RO18547290, HU12892312, MT11679112, LV40003521600, LT119511515, CZ25123891, BG175074752, CY10259033P, EL094259216, IE6433435F, IE8D79739I", map[string]string{
+		"RO18547290": "EU_VAT", "HU12892312": "EU_VAT", "MT11679112": "EU_VAT", "LV40003521600": "EU_VAT", "LT119511515": "EU_VAT",
+		"CZ25123891": "EU_VAT", "BG175074752": "EU_VAT", "CY10259033P": "EU_VAT", "EL094259216": "EU_VAT", "IE6433435F": "EU_VAT", "IE8D79739I": "EU_VAT",
+	})
+	// Capitalized words fit the BIC format; "bank" or "public" nearby is not enough.
+	expectEntities(t, "en-GB", "BANK HOLIDAY: the HOSPITAL is closed", map[string]string{})
+	expectEntities(t, "en-GB", "Public notice: HOSPITAL closed", map[string]string{})
+	expectEntities(t, "en-GB", "BIC: DEUTDEFF", map[string]string{"DEUTDEFF": "SWIFT_BIC"})
+	// Codes, years and dates that only fit a member state's format.
+	expectEntities(t, "de-DE", "RO 2024, RO12, HU 12345678, MT 20240101, LV 12345678901, CZ 12345678, IE6433435E", map[string]string{})
+
+	// Other locales only look for them when a European locale is requested too.
+	expectEntities(t, "en-US", "IBAN DE89 3704 0044 0532 0130 00, VAT DE136695976", map[string]string{})
+	_, found := analyzeLocales(t, model.DetectionRequest{Text: "IBAN DE89 3704 0044 0532 0130 00", Locales: []string{"en-US", "de-DE"}})
+	if found["DE89 3704 0044 0532 0130 00"] != "IBAN" {
+		t.Errorf("expected the IBAN with de-DE requested, got %v", found)
+	}
+
+	expectEntities(t, "de-DE", "Steuer-ID 86095742719", map[string]string{"86095742719": "DE_TAX_ID"})
+	expectEntities(t, "fr-FR", "NIR 2 69 05 49 588 157 80", map[string]string{"2 69 05 49 588 157 80": "FR_NIR"})
+	expectEntities(t, "es-ES", "DNI 12345678Z, NIE X1234567L, not 12345678A", map[string]string{
+		"12345678Z": "ES_DNI", "X1234567L": "ES_NIE",
+	})
+	expectEntities(t, "it-IT", "Codice fiscale RSSMRA85T10A562S, not RSSMRA85T10A562T", map[string]string{"RSSMRA85T10A562S": "IT_FISCAL_CODE"})
+	expectEntities(t, "nl-NL", "BSN 111222333, not 111222334", map[string]string{"111222333": "NL_BSN"})
+	// A BSN needs its keyword.
+	expectEntities(t, "nl-NL", "Order 111222333", map[string]string{})
+}
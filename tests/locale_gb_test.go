@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+// expectEntities runs text through a pipeline in locale and checks it finds exactly
+// the wanted entity types, keyed by matched text.
+func expectEntities(t *testing.T, locale, text string, want map[string]string) {
+	t.Helper()
+	p := detector.NewPipeline(locale, false)
+	detections, err := p.Detect(context.Background(), model.DetectionRequest{Text: text, Locale: locale})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]string)
+	for _, d := range detections {
+		got[d.Text] = d.EntityType
+	}
+	for match, entityType := range want {
+		if got[match] != entityType {
+			t.Errorf("%q: expected %q as %s, got %v", text, match, entityType, got)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("%q: expected %d detections, got %v", text, len(want), got)
+	}
+}
+
+func TestLocale_GB(t *testing.T) {
+	expectEntities(t, "en-GB", "NI number AB 12 34 56 C, not BG 12 34 56 A", map[string]string{"AB 12 34 56 C": "UK_NINO"})
+	expectEntities(t, "en-GB", "NHS number 943 476 5919, not 943 476 5918", map[string]string{"943 476 5919": "UK_NHS_NUMBER"})
+	expectEntities(t, "en-GB", "Deliver to SW1A 1AA or M1 1AE", map[string]string{"SW1A 1AA": "UK_POSTCODE", "M1 1AE": "UK_POSTCODE"})
+	expectEntities(t, "en-GB", "Call 020 7946 0958 or +44 7700 900123", map[string]string{"020 7946 0958": "PHONE_UK", "+44 7700 900123": "PHONE_UK"})
+	expectEntities(t, "en-GB", "Pay sort code 12-34-56 account 12345678", map[string]string{"12-34-56": "UK_SORT_CODE", "12345678": "UK_BANK_ACCOUNT"})
+	expectEntities(t, "en-GB", "Passport 123456789, licence MORGA753116SM9IJ", map[string]string{
+		"123456789": "UK_PASSPORT", "MORGA753116SM9IJ": "UK_DRIVING_LICENCE",
+	})
+	// Bare numbers without keywords are not reported.
+	expectEntities(t, "en-GB", "Order 123456789 shipped on 12-03-24", map[string]string{})
+}
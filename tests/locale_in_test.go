@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+)
+
+func TestLocale_IN(t *testing.T) {
+	expectEntities(t, "en-IN", "Aadhaar 2341 2341 2346, not 2341 2341 2345", map[string]string{"2341 2341 2346": "IN_AADHAAR"})
+	expectEntities(t, "en-IN", "PAN ABCPE1234F, GSTIN 27AAPFU0939F1ZV, not 27AAPFU0939F1ZW", map[string]string{
+		"ABCPE1234F": "IN_PAN", "27AAPFU0939F1ZV": "IN_GSTIN",
+	})
+	expectEntities(t, "en-IN", "Call +91 98765 43210 or 09876543210", map[string]string{
+		"+91 98765 43210": "PHONE_IN", "09876543210": "PHONE_IN",
+	})
+	expectEntities(t, "en-IN", "IFSC HDFC0001234, pay ravi.kumar@okhdfcbank or ravi@acme.com", map[string]string{
+		"HDFC0001234": "IN_IFSC", "ravi.kumar@okhdfcbank": "IN_UPI_ID", "ravi@acme.com": "EMAIL",
+	})
+}
+
+func TestRedactor_MaskAadhaar(t *testing.T) {
+	text := "Aadhaar 2341 2341 2346, PAN ABCPE1234F"
+	req := model.RedactionRequest{DetectionRequest: model.DetectionRequest{Text: text, Locale: "en-IN"}, Mode: model.MaskMode}
+	res, err := redactor.NewRedactor(nil).RedactText(context.Background(), detector.NewPipeline("en-IN", false), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Aadhaar **** **** 2346, PAN **********"; res.RedactedText != want {
+		t.Errorf("expected %q, got %q", want, res.RedactedText)
+	}
+}
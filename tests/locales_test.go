@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+func analyzeLocales(t *testing.T, req model.DetectionRequest) ([]string, map[string]string) {
+	t.Helper()
+	res, err := detector.NewPipeline("en-US", false).Analyze(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]string)
+	for _, d := range res.Detections {
+		found[d.Text] = d.EntityType
+	}
+	return res.Locales, found
+}
+
+func TestLocales_Multiple(t *testing.T) {
+	locales, found := analyzeLocales(t, model.DetectionRequest{
+		Text:    "NI number AB 12 34 56 C, Steuer-ID 86095742719, mail a@b.de",
+		Locale:  "en-GB",
+		Locales: []string{"de-DE", "en-GB"},
+	})
+	if !reflect.DeepEqual(locales, []string{"en-GB", "de-DE"}) {
+		t.Errorf("expected en-GB and de-DE, got %v", locales)
+	}
+	want := map[string]string{"AB 12 34 56 C": "UK_NINO", "86095742719": "DE_TAX_ID", "a@b.de": "EMAIL"}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("expected %v, got %v", want, found)
+	}
+}
+
+func TestLocales_Auto(t *testing.T) {
+	cases := []struct {
+		text    string
+		locales []string
+	}{
+		{"Meine Steuer-ID ist 86095742719, bitte nicht weitergeben", []string{"de-DE"}},
+		{"Please update the NHS number for my account and call me on +44 20 7946 0958", []string{"en-GB"}},
+		{"マイナンバーは1234 5678 9018です", []string{"ja-JP"}},
+		{"Hello, my number is 4111 1111 1111 1111 and the card is for you. Ich bin nicht sicher, ist das mit der Karte ok?", []string{"en-US", "de-DE"}},
+		{"12345", []string{"en-US"}},
+	}
+	for _, c := range cases {
+		if locales, _ := analyzeLocales(t, model.DetectionRequest{Text: c.text, Locale: "auto"}); !reflect.DeepEqual(locales, c.locales) {
+			t.Errorf("%q: expected %v, got %v", c.text, c.locales, locales)
+		}
+	}
+
+	_, found := analyzeLocales(t, model.DetectionRequest{Text: "マイナンバーは1234 5678 9018です", Locales: []string{"auto"}})
+	if found["1234 5678 9018"] != "JP_MY_NUMBER" {
+		t.Errorf("expected the My Number with auto, got %v", found)
+	}
+}
+
+func TestLocales_NERLanguage(t *testing.T) {
+	text := "Later, John Smith met Mary Jones in Paris."
+	detections, err := detector.NewNERDetector().Detect(context.Background(), model.DetectionRequest{Text: text, Locale: "de-DE", Locales: []string{"de-DE"}})
+	if err != nil || len(detections) != 0 {
+		t.Errorf("expected the English NER model to skip German text, got %v, %v", detections, err)
+	}
+
+	languages := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req detector.RemoteNERRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		languages <- req.Documents[0].Language
+		json.NewEncoder(w).Encode(detector.RemoteNERResponse{Documents: []detector.RemoteNERResult{{ID: req.Documents[0].ID}}})
+	}))
+	defer srv.Close()
+	remote := detector.NewRemoteNERDetector(detector.RemoteNERConfig{URL: srv.URL})
+	defer remote.Close()
+
+	p := detector.NewPipeline("en-US", false)
+	p.Registry().Register(remote, detector.DetectorOptions{})
+	if _, err := p.Analyze(context.Background(), model.DetectionRequest{Text: "Je suis Marie et mon numéro est le 0612345678, merci", Locale: "auto"}); err != nil {
+		t.Fatal(err)
+	}
+	if language := <-languages; language != "fr" {
+		t.Errorf("expected the sidecar to be asked for French, got %q", language)
+	}
+}
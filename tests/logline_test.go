@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/handler"
+	"github.com/asoasis/pii-redaction-api/internal/logline"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+)
+
+func TestLogline_Process(t *testing.T) {
+	p := logline.NewProcessor(detector.NewPipeline("en-US", false), redactor.NewRedactor(nil))
+	input := strings.Join([]string{
+		`{"level":"info","msg":"signup from jane@acme.com","token":"abc","n":1}`,
+		`level=info msg="refund to 4111 1111 1111 1111" user=42`,
+		`{"level":"debug","msg":"nothing to see"}`,
+		`{"broken":`,
+	}, "\n")
+
+	tests := []struct {
+		name   string
+		policy logline.MalformedPolicy
+		want   []string
+	}{
+		{
+			name:   "pass malformed",
+			policy: logline.MalformedPass,
+			want: []string{
+				`{"level":"info","msg":"signup from [EMAIL]","token":"[REDACTED]","n":1}`,
+				`level=info msg="refund to [CREDIT_CARD]" user=42`,
+				`{"level":"debug","msg":"nothing to see"}`,
+				`{"broken":`,
+			},
+		},
+		{
+			name:   "drop malformed",
+			policy: logline.MalformedDrop,
+			want: []string{
+				`{"level":"info","msg":"signup from [EMAIL]","token":"[REDACTED]","n":1}`,
+				`level=info msg="refund to [CREDIT_CARD]" user=42`,
+				`{"level":"debug","msg":"nothing to see"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			stats, err := p.Process(context.Background(), strings.NewReader(input), &out, logline.Options{
+				RedactFields: []string{"token"},
+				Malformed:    tt.policy,
+				Request:      model.RedactionRequest{Mode: model.ReplaceMode},
+			})
+			if err != nil {
+				t.Fatalf("Process failed: %v", err)
+			}
+			got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("unexpected output:\ngot:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if stats.Malformed != 1 || stats.EntitiesFound != 2 {
+				t.Errorf("unexpected stats: %+v", stats)
+			}
+		})
+	}
+}
+
+// failOn is a detector that fails on text containing a marker.
+type failOn string
+
+func (f failOn) Detect(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+	if strings.Contains(req.Text, string(f)) {
+		return nil, errors.New("detector failed")
+	}
+	return detector.NewPipeline("en-US", false).Detect(ctx, req)
+}
+
+func TestLogsHandler_Error(t *testing.T) {
+	h := handler.NewLogsHandler(logline.NewProcessor(failOn("boom"), redactor.NewRedactor(nil)), logline.Options{Malformed: logline.MalformedPass})
+	send := func(body string) *http.Response {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/redact/logs", strings.NewReader(body)))
+		return rec.Result()
+	}
+
+	// Lines redacted before the failure are sent, and the trailer reports it.
+	res := send("msg=\"mail jane@acme.com\"\nmsg=boom\nmsg=after\n")
+	body, _ := io.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK || string(body) != "msg=\"mail [EMAIL]\"\n" {
+		t.Errorf("expected the first line, got %d %q", res.StatusCode, body)
+	}
+	if res.Trailer.Get("X-Error") == "" || res.Trailer.Get("X-Lines-Processed") != "2" {
+		t.Errorf("expected the error in the trailer, got %v", res.Trailer)
+	}
+
+	// Without output yet, the status code can still report the failure.
+	if res := send("msg=boom\n"); res.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", res.StatusCode)
+	}
+}
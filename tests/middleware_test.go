@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/middleware"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+)
+
+func TestAuth_BindsTenantToKey(t *testing.T) {
+	keys, err := middleware.NewAPIKeys("sk_default", map[string]string{"acme": "sk_acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := middleware.NewAPIKeys("sk_default", map[string]string{"acme": "sk_default"}); err == nil {
+		t.Error("expected a key shared by two tenants to be rejected")
+	}
+
+	var got string
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = tenant.FromContext(r.Context()) })
+	tests := []struct {
+		name, key string
+		handler   http.Handler
+		code      int
+		tenant    string
+	}{
+		{"Default key", "sk_default", middleware.Auth(keys)(echo), http.StatusOK, tenant.Default},
+		{"Tenant key", "sk_acme", middleware.Auth(keys)(echo), http.StatusOK, "acme"},
+		{"Unknown key", "sk_other", middleware.Auth(keys)(echo), http.StatusUnauthorized, ""},
+		{"Anonymous", "", middleware.Tenant(keys)(echo), http.StatusOK, tenant.Default},
+		{"Anonymous route with a tenant key", "sk_acme", middleware.Tenant(keys)(echo), http.StatusOK, "acme"},
+	}
+	for _, tt := range tests {
+		got = ""
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.key != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.key)
+		}
+		// A tenant named in a header is ignored: only the key decides.
+		req.Header.Set("X-Tenant-ID", "globex")
+		rec := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rec, req)
+		if rec.Code != tt.code || got != tt.tenant {
+			t.Errorf("%s: expected %d as %q, got %d as %q", tt.name, tt.code, tt.tenant, rec.Code, got)
+		}
+	}
+}
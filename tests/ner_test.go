@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+func TestNERDetector_Offsets(t *testing.T) {
+	text := "Later, John Smith met Mary Jones in Paris. “John Smith” said John Smith works there."
+	detections, err := detector.NewNERDetector().Detect(context.Background(), model.DetectionRequest{Text: text})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	johns := 0
+	for _, d := range detections {
+		if text[d.Start:d.End] != d.Text {
+			t.Errorf("offsets [%d:%d] do not match %q", d.Start, d.End, d.Text)
+		}
+		if d.EntityType == "PERSON" && d.Text == "John Smith" {
+			johns++
+		}
+	}
+	if johns != 3 {
+		t.Errorf("expected all 3 mentions of John Smith, got %+v", detections)
+	}
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+func TestRegexDetector_Normalization(t *testing.T) {
+	d := detector.NewRegexDetector("en-US")
+	tests := []struct {
+		text, entityType, match string
+	}{
+		{"SSN １２３-４５-６７８９ on file", "SSN", "１２３-４５-６７８９"},
+		{"SSN 123-45-\u200b6789 on file", "SSN", "123-45-\u200b6789"},
+		{"SSN 1 2 3 - 4 5 - 6 7 8 9 on file", "SSN", "1 2 3 - 4 5 - 6 7 8 9"},
+		{"write to john [at] acme [dot] com today", "EMAIL", "john [at] acme [dot] com"},
+		{"write to john at acme dot co dot uk today", "EMAIL", "john at acme dot co dot uk"},
+		{"write to jоhn@аcme.com today", "EMAIL", "jоhn@аcme.com"}, // Cyrillic о and а
+	}
+	for _, tt := range tests {
+		detections, err := d.Detect(context.Background(), model.DetectionRequest{Text: tt.text, Explain: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found *model.Detection
+		for i := range detections {
+			if detections[i].EntityType == tt.entityType {
+				found = &detections[i]
+			}
+		}
+		if found == nil {
+			t.Errorf("%q: no %s found in %+v", tt.text, tt.entityType, detections)
+			continue
+		}
+		if found.Text != tt.match || tt.text[found.Start:found.End] != tt.match {
+			t.Errorf("%q: expected %q, got %q [%d:%d]", tt.text, tt.match, found.Text, found.Start, found.End)
+		}
+		if found.Explanation.Normalized == "" {
+			t.Errorf("%q: expected the normalized match in the explanation", tt.text)
+		}
+	}
+
+	// Cyrillic words are left alone, and normalization can be turned off.
+	if detections, _ := d.Detect(context.Background(), model.DetectionRequest{Text: "Привет, мир"}); len(detections) != 0 {
+		t.Errorf("unexpected detections: %+v", detections)
+	}
+	// "at" before a domain is usually prose, unless "dot" is spelled out too or the
+	// text is a transcript.
+	for _, text := range []string{"Visit us at acme.com", "Look at example.org for details", "Meet me at noon.com is a site"} {
+		if detections, _ := d.Detect(context.Background(), model.DetectionRequest{Text: text}); len(detections) != 0 {
+			t.Errorf("%q: expected no detections, got %+v", text, detections)
+		}
+	}
+	detections, _ := d.Detect(context.Background(), model.DetectionRequest{Text: "email john at acme.com", Transcript: true})
+	if len(detections) != 1 || detections[0].Text != "john at acme.com" {
+		t.Errorf("expected the email in the transcript, got %+v", detections)
+	}
+
+	d.SetNormalization(false)
+	if detections, _ := d.Detect(context.Background(), model.DetectionRequest{Text: tests[3].text}); len(detections) != 0 {
+		t.Errorf("expected no detections without normalization, got %+v", detections)
+	}
+}
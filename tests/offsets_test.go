@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/handler"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+)
+
+func TestHandlers_OffsetUnit(t *testing.T) {
+	// "Café 😀 " is 11 bytes, 7 runes and 8 UTF-16 code units.
+	text := "Café 😀 SSN 123-45-6789"
+	pipeline := detector.NewPipeline("en-US", false)
+	detect := handler.NewDetectHandler(pipeline)
+	redact := handler.NewRedactHandler(pipeline, redactor.NewRedactor(nil))
+
+	for unit, start := range map[string]int{"": 15, "bytes": 15, "runes": 11, "utf16": 12} {
+		body, _ := json.Marshal(map[string]any{"text": text, "offset_unit": unit, "mode": "mask"})
+
+		rec := httptest.NewRecorder()
+		detect.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/detect", strings.NewReader(string(body))))
+		var detected model.DetectionResponse
+		json.NewDecoder(rec.Body).Decode(&detected)
+		if len(detected.Detections) != 1 || detected.Detections[0].Start != start || detected.Detections[0].End != start+11 {
+			t.Errorf("%q: expected detection at [%d:%d], got %+v", unit, start, start+11, detected.Detections)
+		}
+
+		rec = httptest.NewRecorder()
+		redact.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/redact", strings.NewReader(string(body))))
+		var redacted model.RedactionResponse
+		json.NewDecoder(rec.Body).Decode(&redacted)
+		if len(redacted.Detections) != 1 || redacted.Detections[0].OriginalStart != start || redacted.Detections[0].OriginalEnd != start+11 {
+			t.Errorf("%q: expected redaction at [%d:%d], got %+v", unit, start, start+11, redacted.Detections)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	detect.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/detect", strings.NewReader(`{"text": "x", "offset_unit": "chars"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown offset_unit, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,187 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/handler"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/otlp"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const sampleOTLPLogs = `{
+  "resourceLogs": [{
+    "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "checkout"}}]},
+    "scopeLogs": [{
+      "scope": {"name": "app"},
+      "logRecords": [{
+        "timeUnixNano": "1700000000000000000",
+        "severityNumber": 9,
+        "body": {"stringValue": "payment from jane@acme.com with card 4111 1111 1111 1111"},
+        "attributes": [
+          {"key": "user.email", "value": {"stringValue": "jane@acme.com"}},
+          {"key": "http.route", "value": {"stringValue": "/pay/jane@acme.com"}},
+          {"key": "retry", "value": {"intValue": "2"}}
+        ]
+      }]
+    }]
+  }]
+}`
+
+func TestOTLP_LogsHandler(t *testing.T) {
+	var exported []byte
+	fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exported, _ = io.ReadAll(r.Body)
+		w.Write([]byte("{}"))
+	}))
+	defer fake.Close()
+
+	processor := otlp.NewProcessor(detector.NewPipeline("en-US", false), redactor.NewRedactor(nil), otlp.Options{
+		Attributes: []string{"user.email"},
+		Request:    model.RedactionRequest{Mode: model.ReplaceMode},
+	})
+	h := handler.NewOTLPLogsHandler(processor, otlp.NewExporter(fake.URL, nil, 0))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(sampleOTLPLogs))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var got struct {
+		ResourceLogs []struct {
+			ScopeLogs []struct {
+				LogRecords []struct {
+					TimeUnixNano string `json:"timeUnixNano"`
+					Body         struct {
+						StringValue string `json:"stringValue"`
+					} `json:"body"`
+					Attributes []struct {
+						Key   string          `json:"key"`
+						Value json.RawMessage `json:"value"`
+					} `json:"attributes"`
+				} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	if err := json.Unmarshal(exported, &got); err != nil {
+		t.Fatalf("exported payload is not valid JSON: %v", err)
+	}
+	record := got.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if record.Body.StringValue != "payment from [EMAIL] with card [CREDIT_CARD]" {
+		t.Errorf("unexpected body: %q", record.Body.StringValue)
+	}
+	if record.TimeUnixNano != "1700000000000000000" {
+		t.Errorf("timeUnixNano changed: %q", record.TimeUnixNano)
+	}
+	attrs := map[string]string{}
+	for _, a := range record.Attributes {
+		attrs[a.Key] = string(a.Value)
+	}
+	if attrs["user.email"] != `{"stringValue":"[EMAIL]"}` {
+		t.Errorf("selected attribute not redacted: %s", attrs["user.email"])
+	}
+	if attrs["http.route"] != `{"stringValue":"/pay/jane@acme.com"}` {
+		t.Errorf("unselected attribute changed: %s", attrs["http.route"])
+	}
+	if attrs["retry"] != `{"intValue":"2"}` {
+		t.Errorf("non-string attribute changed: %s", attrs["retry"])
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader(sampleOTLPLogs))
+	req.Header.Set("Content-Type", "text/plain")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("expected 415 for text/plain, got %d", rec.Code)
+	}
+}
+
+func TestOTLP_LogsHandlerProtobuf(t *testing.T) {
+	var exported []byte
+	var contentType string
+	fake := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exported, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+		w.Header().Set("Content-Type", "application/x-protobuf")
+	}))
+	defer fake.Close()
+
+	processor := otlp.NewProcessor(detector.NewPipeline("en-US", false), redactor.NewRedactor(nil), otlp.Options{
+		Attributes: []string{"user.email"},
+		Request:    model.RedactionRequest{Mode: model.ReplaceMode},
+	})
+	h := handler.NewOTLPLogsHandler(processor, otlp.NewExporter(fake.URL, nil, 0))
+
+	str := func(s string) *commonpb.AnyValue {
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+	}
+	body, err := proto.Marshal(&collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{
+			ScopeLogs: []*logspb.ScopeLogs{{
+				LogRecords: []*logspb.LogRecord{{
+					TimeUnixNano: 1700000000000000000,
+					Body:         str("payment from jane@acme.com with card 4111 1111 1111 1111"),
+					Attributes: []*commonpb.KeyValue{
+						{Key: "user.email", Value: str("jane@acme.com")},
+						{Key: "http.route", Value: str("/pay/jane@acme.com")},
+					},
+				}},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/logs", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("expected 200 with a protobuf response, got %d %q: %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+	if contentType != "application/x-protobuf" {
+		t.Errorf("expected the export to stay protobuf, got %q", contentType)
+	}
+	var got collogspb.ExportLogsServiceRequest
+	if err := proto.Unmarshal(exported, &got); err != nil {
+		t.Fatalf("exported payload is not a valid ExportLogsServiceRequest: %v", err)
+	}
+	record := got.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if record.Body.GetStringValue() != "payment from [EMAIL] with card [CREDIT_CARD]" {
+		t.Errorf("unexpected body: %q", record.Body.GetStringValue())
+	}
+	if record.TimeUnixNano != 1700000000000000000 {
+		t.Errorf("timeUnixNano changed: %d", record.TimeUnixNano)
+	}
+	if v := record.Attributes[0].Value.GetStringValue(); v != "[EMAIL]" {
+		t.Errorf("selected attribute not redacted: %q", v)
+	}
+	if v := record.Attributes[1].Value.GetStringValue(); v != "/pay/jane@acme.com" {
+		t.Errorf("unselected attribute changed: %q", v)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/v1/logs", strings.NewReader("\xff\xff"))
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid protobuf body, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/store"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
+	"github.com/asoasis/pii-redaction-api/internal/tenantconfig"
+)
+
+func newPatternService(t *testing.T, dir string) (*detector.Pipeline, *tenantconfig.PatternService) {
+	t.Helper()
+	configStore, err := store.NewConfigStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := detector.NewPipeline("en-US", false)
+	regex, _ := p.Registry().Get("regex")
+	svc := tenantconfig.NewPatternService(configStore, regex.(*detector.RegexDetector))
+	if err := svc.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return p, svc
+}
+
+func TestPatternService_HotLoad(t *testing.T) {
+	dir := t.TempDir()
+	p, svc := newPatternService(t, dir)
+	acme := tenant.WithID(context.Background(), "acme")
+	text := "Employee EMP-004211 filed claim CLM-77"
+
+	employeeID := model.CustomPattern{Name: "EMPLOYEE_ID", Regex: `\bEMP-\d{6}\b`, Confidence: 0.55, ContextKeywords: []string{"Employee"}}
+	if err := svc.Create(acme, "acme", employeeID); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := svc.Create(acme, "acme", employeeID); !errors.Is(err, tenantconfig.ErrPatternExists) {
+		t.Errorf("expected ErrPatternExists, got %v", err)
+	}
+	if err := svc.Create(acme, "acme", model.CustomPattern{Name: "BAD", Regex: "(", Confidence: 0.9}); !errors.Is(err, detector.ErrInvalidPattern) {
+		t.Errorf("expected ErrInvalidPattern, got %v", err)
+	}
+	if err := svc.Create(acme, "acme", model.CustomPattern{Name: "SSN", Regex: `\d{9}`, Confidence: 0.9}); !errors.Is(err, detector.ErrInvalidPattern) {
+		t.Errorf("expected a built-in entity type to be rejected, got %v", err)
+	}
+
+	detections, _ := p.Detect(acme, model.DetectionRequest{Text: text})
+	if len(detections) != 1 || detections[0].EntityType != "EMPLOYEE_ID" || detections[0].Confidence < 0.64 || detections[0].DetectionMethod != "custom" {
+		t.Errorf("expected keyword-boosted custom EMPLOYEE_ID, got %+v", detections)
+	}
+	if detections, _ := p.Detect(context.Background(), model.DetectionRequest{Text: text}); len(detections) != 0 {
+		t.Errorf("pattern leaked to another tenant: %+v", detections)
+	}
+
+	// A fresh pipeline loads the persisted pattern at startup.
+	p2, svc2 := newPatternService(t, dir)
+	if detections, _ := p2.Detect(acme, model.DetectionRequest{Text: text}); len(detections) != 1 {
+		t.Errorf("persisted pattern not loaded: %+v", detections)
+	}
+
+	if err := svc2.Delete(acme, "acme", "EMPLOYEE_ID"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if detections, _ := p2.Detect(acme, model.DetectionRequest{Text: text}); len(detections) != 0 {
+		t.Errorf("deleted pattern still matches: %+v", detections)
+	}
+}
+
+func TestPatternService_ValidatorOnShortMatch(t *testing.T) {
+	p, svc := newPatternService(t, t.TempDir())
+	acme := tenant.WithID(context.Background(), "acme")
+	// The validators expect the format of their built-in pattern; a tenant's pattern can
+	// hand them anything.
+	for _, validator := range []string{"bic", "fr_nir", "es_dni", "it_fiscal_code", "ssn", "ipv4"} {
+		if err := svc.Create(acme, "acme", model.CustomPattern{Name: "REF_" + strings.ToUpper(validator), Regex: `\bREF\d{2}\b`, Confidence: 0.9, Validator: validator}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	detections, err := p.Detect(acme, model.DetectionRequest{Text: "See REF12"})
+	if err != nil || len(detections) != 0 {
+		t.Errorf("expected no detections, got %+v, %v", detections, err)
+	}
+}
+
+func TestValidators_ShortInput(t *testing.T) {
+	// Validators are called directly with every prefix of each text, the empty one
+	// included, as well as through matches.
+	texts := []string{"A", "12", "A1", "REF12", "+91", "2A", "G-1", "ÿ", "SW1"}
+	for _, name := range detector.ValidatorNames() {
+		p, err := detector.CompilePattern(model.CustomPattern{Name: "SHORT_INPUT", Regex: `\S{1,3}`, Confidence: 0.9, Validator: name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, text := range texts {
+			for i := 0; i <= len(text); i++ {
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							t.Errorf("validator %s panicked on %q: %v", name, text[:i], r)
+						}
+					}()
+					p.Validator(text[:i])
+				}()
+			}
+			detector.MatchPatterns(text, []detector.RegexPattern{p})
+		}
+	}
+}
+
+func TestCompilePattern_ZeroWidth(t *testing.T) {
+	for _, regex := range []string{`\b`, `(?:x)?\b`, `a*`, `$`} {
+		if _, err := detector.CompilePattern(model.CustomPattern{Name: "ZERO_WIDTH", Regex: regex, Confidence: 0.9}); !errors.Is(err, detector.ErrInvalidPattern) {
+			t.Errorf("%s: expected ErrInvalidPattern, got %v", regex, err)
+		}
+	}
+}
+
+func TestRefresher_PicksUpOtherInstances(t *testing.T) {
+	dir := t.TempDir()
+	_, writer := newPatternService(t, dir)
+	configStore, err := store.NewConfigStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, patterns := newPatternService(t, dir)
+	rules := tenantconfig.NewRuleService(configStore, p.ContextAnalyzer())
+	policies := tenantconfig.NewPolicyService(configStore, p)
+	refresher := tenantconfig.NewRefresher(time.Nanosecond, patterns, rules, policies)
+
+	acme := tenant.WithID(context.Background(), "acme")
+	text := "Employee EMP-004211 filed a claim"
+	if err := writer.Create(acme, "acme", model.CustomPattern{Name: "EMPLOYEE_ID", Regex: `\bEMP-\d{6}\b`, Confidence: 0.9}); err != nil {
+		t.Fatal(err)
+	}
+	if detections, _ := p.Detect(acme, model.DetectionRequest{Text: text}); len(detections) != 0 {
+		t.Fatalf("expected the other instance to need a refresh, got %+v", detections)
+	}
+
+	if err := refresher.Refresh(acme, "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if detections, _ := p.Detect(acme, model.DetectionRequest{Text: text}); len(detections) != 1 {
+		t.Errorf("expected the refreshed pattern to match, got %+v", detections)
+	}
+
+	if err := writer.Delete(acme, "acme", "EMPLOYEE_ID"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	if err := refresher.Refresh(acme, "acme"); err != nil {
+		t.Fatal(err)
+	}
+	if detections, _ := p.Detect(acme, model.DetectionRequest{Text: text}); len(detections) != 0 {
+		t.Errorf("expected the deleted pattern to be gone after a refresh, got %+v", detections)
+	}
+}
@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+)
+
+func TestPhoneDetector(t *testing.T) {
+	d := detector.NewPhoneDetector("en-US")
+	tests := []struct {
+		locale, text, match, entityType string
+		metadata                        map[string]string
+	}{
+		{"en-US", "call (415) 555-2671 today", "(415) 555-2671", "PHONE_US",
+			map[string]string{"e164": "+14155552671", "country": "US", "line_type": "fixed_line_or_mobile"}},
+		{"en-US", "call 1-800-555-0199", "1-800-555-0199", "PHONE_US",
+			map[string]string{"e164": "+18005550199", "country": "US", "line_type": "toll_free"}},
+		{"en-US", "Vancouver office 604.555.0123", "604.555.0123", "PHONE",
+			map[string]string{"e164": "+16045550123", "country": "CA", "line_type": "fixed_line_or_mobile"}},
+		{"en-US", "London: +44 (0)20 7946 0958", "+44 (0)20 7946 0958", "PHONE_UK",
+			map[string]string{"e164": "+442079460958", "country": "GB", "line_type": "fixed_line"}},
+		{"en-US", "Handy +49 151 23456789", "+49 151 23456789", "PHONE",
+			map[string]string{"e164": "+4915123456789", "country": "DE", "line_type": "mobile"}},
+		{"en-GB", "mobile 07700 900123", "07700 900123", "PHONE_UK",
+			map[string]string{"e164": "+447700900123", "country": "GB", "line_type": "mobile"}},
+		{"fr-FR", "appelez le 06 12 34 56 78", "06 12 34 56 78", "PHONE",
+			map[string]string{"e164": "+33612345678", "country": "FR", "line_type": "mobile"}},
+		{"en-IN", "WhatsApp 0091 98765 43210", "0091 98765 43210", "PHONE_IN",
+			map[string]string{"e164": "+919876543210", "country": "IN", "line_type": "mobile"}},
+	}
+	for _, tt := range tests {
+		detections, err := d.Detect(context.Background(), model.DetectionRequest{Text: tt.text, Locale: tt.locale})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(detections) != 1 || detections[0].Text != tt.match || detections[0].EntityType != tt.entityType ||
+			!reflect.DeepEqual(detections[0].Metadata, tt.metadata) {
+			t.Errorf("%q: expected %q as %s with %v, got %+v", tt.text, tt.match, tt.entityType, tt.metadata, detections)
+		}
+	}
+
+	// Invalid area codes and exchanges, numbers of another region written nationally,
+	// and other kinds of numbers are not phone numbers.
+	for _, text := range []string{
+		"ref 123-456-7890", "call 415-055-2671", "dial 911-555-1234", "06 12 34 56 78",
+		"date 2024-01-15", "CPF 529.982.247-25", "order A4155552671",
+	} {
+		if detections, _ := d.Detect(context.Background(), model.DetectionRequest{Text: text}); len(detections) != 0 {
+			t.Errorf("%q: expected no phone numbers, got %+v", text, detections)
+		}
+	}
+
+	// Numbers separated by spaces are reported separately.
+	detections, _ := d.Detect(context.Background(), model.DetectionRequest{Text: "415-555-2671 415-555-2672"})
+	if len(detections) != 2 || detections[1].Text != "415-555-2672" {
+		t.Errorf("expected two numbers, got %+v", detections)
+	}
+
+	text := "call me at five five five, eight six seven, fifty three oh nine"
+	detections, _ = d.Detect(context.Background(), model.DetectionRequest{Text: text, Transcript: true})
+	if len(detections) != 1 || detections[0].Text != "five five five, eight six seven, fifty three oh nine" || detections[0].Metadata["e164"] != "+15558675309" {
+		t.Errorf("expected the spoken number, got %+v", detections)
+	}
+}
+
+func TestRedactor_HashPhoneE164(t *testing.T) {
+	text := "call (415) 555-2671 or +1 415 555 2671"
+	req := model.RedactionRequest{DetectionRequest: model.DetectionRequest{Text: text}, Mode: model.HashMode}
+	res, err := redactor.NewRedactor(nil).RedactText(context.Background(), detector.NewPipeline("en-US", false), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Detections) != 2 || res.Detections[0].RedactedValue != res.Detections[1].RedactedValue {
+		t.Errorf("expected both formats to hash alike, got %+v", res.Detections)
+	}
+}
+
+func TestPhoneDetector_LongDigitChain(t *testing.T) {
+	text := strings.Repeat("12 ", 20000)
+	start := time.Now()
+	if _, err := detector.NewPhoneDetector("en-US").Detect(context.Background(), model.DetectionRequest{Text: text}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected a 60 KB digit chain to take linear time, took %v", elapsed)
+	}
+}
+
+func TestPhoneDetector_ExplanationPatternIDs(t *testing.T) {
+	d := detector.NewPhoneDetector("en-US")
+	tests := map[string]string{
+		"Call (415) 555-2671":   "en-US/PHONE_US",
+		"Call +44 20 7946 0958": "en-GB/PHONE_UK",
+		"Call +91 98765 43210":  "en-IN/PHONE_IN",
+		"Call +49 30 1234567":   "phone/DE/fixed_line",
+	}
+	for text, want := range tests {
+		dets, err := d.Detect(context.Background(), model.DetectionRequest{Text: text, Explain: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dets) != 1 || dets[0].Explanation == nil || dets[0].Explanation.Pattern != want {
+			t.Errorf("%q: expected pattern %s, got %+v", text, want, dets)
+		}
+	}
+}
@@ -2,10 +2,19 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/handler"
 	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+	"github.com/asoasis/pii-redaction-api/internal/tenant"
 )
 
 func TestPipeline_Detect(t *testing.T) {
@@ -41,3 +50,210 @@ func TestPipeline_Detect(t *testing.T) {
 		}
 	}
 }
+
+type stubDetector struct {
+	name string
+	fn   func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error)
+}
+
+func (d stubDetector) Name() string { return d.name }
+
+func (d stubDetector) Detect(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+	return d.fn(ctx, req)
+}
+
+func TestPipeline_Registry(t *testing.T) {
+	p := detector.NewPipeline("en-US", false)
+	registry := p.Registry()
+
+	registry.Register(stubDetector{name: "employee_id", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		return []model.Detection{{EntityType: "EMPLOYEE_ID", Text: "E-1234", Start: 4, End: 10, Confidence: 0.9, DetectionMethod: "employee_id"}}, nil
+	}}, detector.DetectorOptions{})
+	registry.Register(stubDetector{name: "panics", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		panic("boom")
+	}}, detector.DetectorOptions{})
+	registry.Register(stubDetector{name: "slow", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		time.Sleep(time.Second)
+		return []model.Detection{{EntityType: "SLOW", Start: 0, End: 3, Confidence: 1, DetectionMethod: "slow"}}, nil
+	}}, detector.DetectorOptions{Timeout: 20 * time.Millisecond})
+
+	detections, err := p.Detect(context.Background(), model.DetectionRequest{Text: "id: E-1234, ssn 123-45-6789"})
+	if err != nil {
+		t.Fatalf("optional detector failures should not fail the pipeline: %v", err)
+	}
+	found := map[string]bool{}
+	for _, d := range detections {
+		found[d.EntityType] = true
+	}
+	if !found["EMPLOYEE_ID"] || !found["SSN"] || found["SLOW"] {
+		t.Errorf("unexpected detections: %+v", detections)
+	}
+
+	if err := registry.SetEnabled("employee_id", false); err != nil {
+		t.Fatal(err)
+	}
+	detections, _ = p.Detect(context.Background(), model.DetectionRequest{Text: "id: E-1234"})
+	if len(detections) != 0 {
+		t.Errorf("disabled detector still ran: %+v", detections)
+	}
+}
+
+func TestPipeline_Thresholds(t *testing.T) {
+	p := detector.NewPipeline("en-US", false)
+	text := "alpha bravo charlie delta"
+	p.Registry().Register(stubDetector{name: "stub", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		return []model.Detection{
+			{EntityType: "PERSON", Start: 0, End: 5, Confidence: 0.70},
+			{EntityType: "SSN", Start: 6, End: 11, Confidence: 0.50},
+			{EntityType: "PHONE_US", Start: 12, End: 19, Confidence: 0.65},
+		}, nil
+	}}, detector.DetectorOptions{})
+	acme := tenant.WithID(context.Background(), "acme")
+	p.SetTenantPolicy("acme", model.DetectionPolicy{EntityThresholds: map[string]float64{"GOV_ID": 0.3}})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		req  model.DetectionRequest
+		want []string
+	}{
+		{"Default threshold", context.Background(), model.DetectionRequest{}, []string{"PERSON", "PHONE_US"}},
+		{"Per-entity and category thresholds", context.Background(), model.DetectionRequest{EntityThresholds: map[string]float64{"GOV_ID": 0.3, "PERSON": 0.9}}, []string{"SSN", "PHONE_US"}},
+		{"Most specific threshold wins", context.Background(), model.DetectionRequest{EntityThresholds: map[string]float64{"PHONE": 0.7, "CONTACT": 0.1}}, []string{"PERSON"}},
+		{"Category filter", context.Background(), model.DetectionRequest{EntityTypes: []string{"CONTACT"}}, []string{"PHONE_US"}},
+		{"Tenant policy", acme, model.DetectionRequest{}, []string{"PERSON", "SSN", "PHONE_US"}},
+		{"Request overrides tenant policy", acme, model.DetectionRequest{EntityThresholds: map[string]float64{"SSN": 0.9}}, []string{"PERSON", "PHONE_US"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Text = text
+			detections, err := p.Detect(tt.ctx, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range detections {
+				got = append(got, d.EntityType)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDetectHandler_RiskSummary(t *testing.T) {
+	detect := handler.NewDetectHandler(detector.NewPipeline("en-GB", false))
+	body := `{"text": "NI number AB 12 34 56 C, call 07700 900123", "locale": "en-GB"}`
+	rec := httptest.NewRecorder()
+	detect.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/detect", strings.NewReader(body)))
+	var detected model.DetectionResponse
+	json.NewDecoder(rec.Body).Decode(&detected)
+	if want := (model.RiskSummary{HIPAARelevant: 2, GDPRRelevant: 2}); detected.RiskSummary != want {
+		t.Errorf("expected UK_NINO and PHONE_UK to count like SSN and PHONE_US, got %+v for %+v", detected.RiskSummary, detected.Detections)
+	}
+}
+
+func TestPipeline_Overlaps(t *testing.T) {
+	p := detector.NewPipeline("en-US", false)
+	text := "alpha bravo charlie delta"
+	p.Registry().Register(stubDetector{name: "stub", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		return []model.Detection{
+			{EntityType: "PERSON", Start: 0, End: 5, Confidence: 0.97},
+			{EntityType: "EMAIL", Start: 0, End: 11, Confidence: 0.95},
+		}, nil
+	}}, detector.DetectorOptions{})
+
+	tests := []struct {
+		name string
+		req  model.DetectionRequest
+		want string
+	}{
+		{"Highest confidence by default", model.DetectionRequest{}, "PERSON"},
+		{"Longest", model.DetectionRequest{OverlapStrategy: model.LongestOverlap}, "EMAIL"},
+		{"Default priority", model.DetectionRequest{OverlapStrategy: model.PriorityOverlap}, "EMAIL"},
+		{"Request priority", model.DetectionRequest{OverlapStrategy: model.PriorityOverlap, EntityPriority: []string{"PERSONAL"}}, "PERSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Text = text
+			detections, err := p.Detect(context.Background(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(detections) != 1 || detections[0].EntityType != tt.want {
+				t.Errorf("expected only %s, got %+v", tt.want, detections)
+			}
+		})
+	}
+
+	if _, err := p.Detect(context.Background(), model.DetectionRequest{Text: text, OverlapStrategy: "random"}); !errors.Is(err, detector.ErrInvalidRequest) {
+		t.Errorf("expected ErrInvalidRequest, got %v", err)
+	}
+
+	req := model.DetectionRequest{Text: text, OverlapStrategy: model.LongestOverlap, IncludeNested: true}
+	detections, _ := p.Detect(context.Background(), req)
+	if len(detections) != 2 || detections[0].ID != "d1" || detections[0].Parent != "" || detections[1].Parent != "d1" {
+		t.Fatalf("expected PERSON nested in EMAIL, got %+v", detections)
+	}
+
+	res, err := redactor.NewRedactor(nil).Redact(context.Background(), text, detections, model.ReplaceMode, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.RedactedText != "[EMAIL] charlie delta" || res.EntitiesFound != 1 {
+		t.Errorf("redactor did not use a non-overlapping cover: %+v", res)
+	}
+}
+
+func TestPipeline_Ensemble(t *testing.T) {
+	p := detector.NewPipeline("en-US", false)
+	text := "alpha bravo charlie delta"
+	p.Registry().Register(stubDetector{name: "stub", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		return []model.Detection{
+			{EntityType: "PERSON", Start: 0, End: 5, Confidence: 0.80},
+			{EntityType: "PHONE", Start: 6, End: 11, Confidence: 0.70},
+		}, nil
+	}}, detector.DetectorOptions{})
+	p.Registry().Register(stubDetector{name: "other", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		return []model.Detection{
+			{EntityType: "PERSON", Start: 0, End: 5, Confidence: 0.50, DetectionMethod: "remote"},
+			{EntityType: "PHONE_US", Start: 6, End: 11, Confidence: 0.60, DetectionMethod: "remote"},
+			{EntityType: "LOCATION", Start: 0, End: 5, Confidence: 0.65, DetectionMethod: "remote"},
+		}, nil
+	}}, detector.DetectorOptions{})
+	if err := p.SetEnsemble(detector.Ensemble{Weights: map[string]float64{"stub": 3}}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := model.DetectionRequest{Text: text, IncludeNested: true}
+	detections, err := p.Detect(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detections) != 3 {
+		t.Fatalf("expected PERSON, nested LOCATION and PHONE_US, got %+v", detections)
+	}
+	person, phone := detections[0], detections[2]
+	if person.EntityType != "PERSON" || !approx(person.Confidence, 0.90) || person.DetectionMethod != "stub" ||
+		strings.Join(person.Methods, ",") != "remote,stub" {
+		t.Errorf("expected noisy-OR PERSON from remote and stub, got %+v", person)
+	}
+	if phone.EntityType != "PHONE_US" || !approx(phone.Confidence, 0.88) {
+		t.Errorf("expected PHONE and PHONE_US to merge into PHONE_US, got %+v", phone)
+	}
+	if detections[1].EntityType != "LOCATION" || len(detections[1].Methods) != 1 || detections[1].Parent == "" {
+		t.Errorf("expected LOCATION to stay separate, got %+v", detections[1])
+	}
+
+	for agg, want := range map[model.EnsembleAggregation]float64{
+		model.WeightedVoteAggregation: 0.725,
+		model.MaxAggregation:          0.80,
+	} {
+		req := model.DetectionRequest{Text: text, EnsembleAggregation: agg}
+		detections, _ := p.Detect(context.Background(), req)
+		if len(detections) == 0 || !approx(detections[0].Confidence, want) {
+			t.Errorf("%s: expected %.3f, got %+v", agg, want, detections)
+		}
+	}
+}
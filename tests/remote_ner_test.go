@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// nerStub answers with a PERSON span for every occurrence of "Alice", in code points.
+func nerStub(t *testing.T, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		var req detector.RemoteNERRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		json.NewEncoder(w).Encode(findAlice(req))
+	}))
+}
+
+func findAlice(req detector.RemoteNERRequest) detector.RemoteNERResponse {
+	var res detector.RemoteNERResponse
+	for _, doc := range req.Documents {
+		result := detector.RemoteNERResult{ID: doc.ID, Entities: []detector.RemoteNEREntity{}}
+		runes := []rune(doc.Text)
+		for i := 0; i+5 <= len(runes); i++ {
+			if string(runes[i:i+5]) == "Alice" {
+				result.Entities = append(result.Entities, detector.RemoteNEREntity{Start: i, End: i + 5, Label: "PER", Score: 0.93})
+			}
+		}
+		res.Documents = append(res.Documents, result)
+	}
+	return res
+}
+
+// grpcNERStub serves detector.RemoteNERMethod with findAlice, checking the API key.
+func grpcNERStub(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recognize := func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+		if md, _ := metadata.FromIncomingContext(ctx); len(md.Get("authorization")) != 1 || md.Get("authorization")[0] != "Bearer secret" {
+			t.Errorf("expected the API key as a bearer token, got %v", md)
+		}
+		in := &structpb.Struct{}
+		if err := dec(in); err != nil {
+			return nil, err
+		}
+		body, err := protojson.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		var req detector.RemoteNERRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("invalid request: %v", err)
+		}
+		if body, err = json.Marshal(findAlice(req)); err != nil {
+			return nil, err
+		}
+		out := &structpb.Struct{}
+		return out, protojson.Unmarshal(body, out)
+	}
+	srv := grpc.NewServer()
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "pii.ner.v1.NER",
+		HandlerType: (*any)(nil),
+		Methods:     []grpc.MethodDesc{{MethodName: "Recognize", Handler: recognize}},
+	}, struct{}{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String()
+}
+
+func TestRemoteNER_GRPC(t *testing.T) {
+	d := detector.NewRemoteNERDetector(detector.RemoteNERConfig{URL: "grpc://" + grpcNERStub(t), APIKey: "secret"})
+	defer d.Close()
+
+	text := "Café owner Alice met Alice"
+	detections, err := d.Detect(context.Background(), model.DetectionRequest{Text: text})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detections) != 2 || detections[0].Start != 12 || text[detections[0].Start:detections[0].End] != "Alice" ||
+		detections[0].EntityType != "PERSON" || detections[0].Confidence != 0.93 {
+		t.Errorf("unexpected detections: %+v", detections)
+	}
+}
+
+func TestRemoteNER_GRPCFallback(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close() // Nothing listens here
+	fallback := stubDetector{name: "ner", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		return []model.Detection{{EntityType: "PERSON", Start: 0, End: 5, Confidence: 0.85, DetectionMethod: "ner"}}, nil
+	}}
+	d := detector.NewRemoteNERDetector(detector.RemoteNERConfig{URL: "grpc://" + addr, Timeout: time.Second, Fallback: fallback})
+	defer d.Close()
+
+	detections, err := d.Detect(context.Background(), model.DetectionRequest{Text: "Alice"})
+	if err != nil || len(detections) != 1 {
+		t.Errorf("expected the fallback's detection, got %+v, %v", detections, err)
+	}
+}
+
+func TestRemoteNER_Batching(t *testing.T) {
+	var calls int32
+	srv := nerStub(t, &calls)
+	defer srv.Close()
+	d := detector.NewRemoteNERDetector(detector.RemoteNERConfig{URL: srv.URL, BatchSize: 8, BatchWindow: 100 * time.Millisecond})
+	defer d.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			text := "Café owner Alice met Alice"
+			detections, err := d.Detect(context.Background(), model.DetectionRequest{Text: text})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(detections) != 2 || detections[0].Start != 12 || text[detections[0].Start:detections[0].End] != "Alice" ||
+				detections[0].EntityType != "PERSON" || detections[0].Confidence != 0.93 {
+				t.Errorf("unexpected detections: %+v", detections)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("expected concurrent calls to share one batch, got %d requests", calls)
+	}
+}
+
+func TestRemoteNER_CircuitBreakerFallback(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	fallback := stubDetector{name: "ner", fn: func(ctx context.Context, req model.DetectionRequest) ([]model.Detection, error) {
+		return []model.Detection{{EntityType: "PERSON", Start: 0, End: 5, Confidence: 0.85, DetectionMethod: "ner"}}, nil
+	}}
+	d := detector.NewRemoteNERDetector(detector.RemoteNERConfig{URL: srv.URL, FailureThreshold: 2, Cooldown: time.Hour, Fallback: fallback})
+	defer d.Close()
+
+	for i := 0; i < 4; i++ {
+		detections, err := d.Detect(context.Background(), model.DetectionRequest{Text: "Alice"})
+		if err != nil || len(detections) != 1 || detections[0].DetectionMethod != "ner" {
+			t.Errorf("call %d: expected fallback detection, got %+v, %v", i, detections, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected the circuit to open after 2 failures, got %d requests", calls)
+	}
+}
+
+func TestRemoteNER_Timeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer srv.Close()
+	d := detector.NewRemoteNERDetector(detector.RemoteNERConfig{URL: srv.URL, Timeout: 20 * time.Millisecond})
+	defer d.Close()
+
+	start := time.Now()
+	_, err := d.Detect(context.Background(), model.DetectionRequest{Text: "Alice"})
+	if err == nil || !strings.Contains(err.Error(), "remote NER request failed") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+	if time.Since(start) > 150*time.Millisecond {
+		t.Errorf("timeout not applied, took %v", time.Since(start))
+	}
+}
+
+func TestRemoteNER_ByteOffsets(t *testing.T) {
+	text := "Café owner Alice"
+	for _, unit := range []string{"bytes", "byte"} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req detector.RemoteNERRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			json.NewEncoder(w).Encode(detector.RemoteNERResponse{OffsetUnit: unit, Documents: []detector.RemoteNERResult{{
+				ID: req.Documents[0].ID, Entities: []detector.RemoteNEREntity{{Start: 12, End: 17, Label: "PER", Score: 0.9}},
+			}}})
+		}))
+		d := detector.NewRemoteNERDetector(detector.RemoteNERConfig{URL: srv.URL})
+		detections, err := d.Detect(context.Background(), model.DetectionRequest{Text: text})
+		d.Close()
+		srv.Close()
+		if err != nil || len(detections) != 1 || text[detections[0].Start:detections[0].End] != "Alice" {
+			t.Errorf("%q: expected Alice at byte offsets, got %+v, %v", unit, detections, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+	"github.com/asoasis/pii-redaction-api/internal/redactor"
+	"github.com/asoasis/pii-redaction-api/internal/syslog"
+)
+
+func TestSyslog_Parse(t *testing.T) {
+	tests := []struct {
+		raw    string
+		format syslog.Format
+		host   string
+		text   string
+	}{
+		{
+			raw:    `<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - ID47 [exampleSDID@32473 iut="3" eventSource="Application]"] login by jane@acme.com`,
+			format: syslog.RFC5424,
+			host:   "mymachine.example.com",
+			text:   "login by jane@acme.com",
+		},
+		{
+			raw:    `<34>1 2003-10-11T22:14:15.003Z web app - - [a@1 path="C:\tmp" q="\]"][b@1] -`,
+			format: syslog.RFC5424,
+			host:   "web",
+			text:   "-",
+		},
+		{
+			raw:    `<13>Oct 11 22:14:15 appliance sshd[412]: Accepted password for bob from 10.1.2.3`,
+			format: syslog.RFC3164,
+			host:   "appliance",
+			text:   "Accepted password for bob from 10.1.2.3",
+		},
+	}
+	for _, tt := range tests {
+		msg := syslog.Parse(tt.raw)
+		if msg.Format != tt.format || msg.Hostname != tt.host || msg.Text != tt.text {
+			t.Errorf("Parse(%q) = %+v", tt.raw, msg)
+		}
+		if msg.String() != tt.raw {
+			t.Errorf("round trip mismatch: %q", msg.String())
+		}
+	}
+}
+
+func TestSyslog_Server(t *testing.T) {
+	downstream, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer downstream.Close()
+
+	forwarder, err := syslog.NewForwarder("udp://" + downstream.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := syslog.NewServer(syslog.Config{
+		UDPAddr: "127.0.0.1:0",
+		TCPAddr: "127.0.0.1:0",
+		Policies: syslog.Policies{
+			Default: syslog.Policy{Mode: model.ReplaceMode},
+			Sources: []syslog.SourcePolicy{
+				{Source: "noisy-host", Policy: syslog.Policy{Drop: true}},
+			},
+			TrustHostnames: true,
+		},
+	}, detector.NewPipeline("en-US", false), redactor.NewRedactor(nil), forwarder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	udp, err := net.Dial("udp", server.UDPAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	fmt.Fprint(udp, "<13>Oct 11 22:14:15 appliance app: ssn 123-45-6789 on file")
+
+	tcp, err := net.Dial("tcp", server.TCPAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+	dropped := "<13>1 2024-01-01T00:00:00Z noisy-host app - - - call 555-867-5309"
+	kept := `<13>1 2024-01-01T00:00:00Z web app - - [origin user="jane@acme.com" note="say \"hi\""] mail jane@acme.com`
+	fmt.Fprintf(tcp, "%d %s%d %s", len(dropped), dropped, len(kept), kept)
+
+	got := map[string]bool{}
+	buf := make([]byte, 2048)
+	downstream.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for len(got) < 2 {
+		n, _, err := downstream.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("expected 2 forwarded messages, got %v: %v", got, err)
+		}
+		got[string(buf[:n])] = true
+	}
+
+	for _, want := range []string{
+		"<13>Oct 11 22:14:15 appliance app: ssn [SSN] on file",
+		`<13>1 2024-01-01T00:00:00Z web app - - [origin user="[EMAIL\]" note="say \"hi\""] mail [EMAIL]`,
+	} {
+		if !got[want] {
+			t.Errorf("missing forwarded message %q in %v", want, got)
+		}
+	}
+	for msg := range got {
+		if strings.Contains(msg, "noisy-host") {
+			t.Errorf("message from dropped source was forwarded: %q", msg)
+		}
+	}
+}
+
+func TestSyslog_HostnamePolicyNeedsTrust(t *testing.T) {
+	forwarder, err := syslog.NewForwarder("file://" + t.TempDir() + "/out.log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer forwarder.Close()
+	policies := syslog.Policies{Sources: []syslog.SourcePolicy{{Source: "lb-01", Policy: syslog.Policy{Passthrough: true}}}}
+	if _, err := syslog.NewServer(syslog.Config{UDPAddr: "127.0.0.1:0", Policies: policies}, detector.NewPipeline("en-US", false), redactor.NewRedactor(nil), forwarder); err == nil {
+		t.Error("expected a hostname source without trust_hostnames to be rejected")
+	}
+}
+
+func TestSyslog_FrameLengthLimit(t *testing.T) {
+	downstream, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer downstream.Close()
+	forwarder, err := syslog.NewForwarder("udp://" + downstream.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := syslog.NewServer(syslog.Config{TCPAddr: "127.0.0.1:0"}, detector.NewPipeline("en-US", false), redactor.NewRedactor(nil), forwarder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	tcp, err := net.Dial("tcp", server.TCPAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcp.Close()
+	// A length prefix that never ends must not be buffered without bound.
+	fmt.Fprint(tcp, strings.Repeat("1", 64))
+	tcp.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var netErr net.Error
+	if _, err := tcp.Read(make([]byte, 1)); err == nil || errors.As(err, &netErr) && netErr.Timeout() {
+		t.Errorf("expected the server to close the connection, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asoasis/pii-redaction-api/internal/detector"
+	"github.com/asoasis/pii-redaction-api/internal/model"
+)
+
+func TestRegexDetector_Transcript(t *testing.T) {
+	d := detector.NewRegexDetector("en-US")
+	tests := []struct {
+		text, entityType, match, normalized string
+	}{
+		{"my social is one two three, forty five, six seven eight nine thanks", "SSN",
+			"one two three, forty five, six seven eight nine", "123-45-6789"},
+		{"social security number one two three four five six seven eight nine", "SSN",
+			"one two three four five six seven eight nine", "123456789"},
+		{"card four one one one, double one one one, one one one one, triple one one", "CREDIT_CARD",
+			"four one one one, double one one one, one one one one, triple one one", "4111-1111-1111-1111"},
+		{"email is John dot Smith at gmail dot com okay", "EMAIL",
+			"John dot Smith at gmail dot com", "John.Smith@gmail.com"},
+	}
+	for _, tt := range tests {
+		detections, err := d.Detect(context.Background(), model.DetectionRequest{Text: tt.text, Transcript: true, Explain: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found *model.Detection
+		for i := range detections {
+			if detections[i].EntityType == tt.entityType && detections[i].Explanation.ValidatorResult != "failed" {
+				found = &detections[i]
+			}
+		}
+		if found == nil {
+			t.Errorf("%q: no %s found in %+v", tt.text, tt.entityType, detections)
+			continue
+		}
+		if found.Text != tt.match || tt.text[found.Start:found.End] != tt.match || found.Explanation.Normalized != tt.normalized {
+			t.Errorf("%q: expected %q read as %q, got %q [%d:%d] read as %q", tt.text, tt.match, tt.normalized,
+				found.Text, found.Start, found.End, found.Explanation.Normalized)
+		}
+	}
+
+	// Number words are only parsed in transcript mode, and invalid numbers are not reported.
+	text := "my social is one two three, forty five, six seven eight nine"
+	if detections, _ := d.Detect(context.Background(), model.DetectionRequest{Text: text}); len(detections) != 0 {
+		t.Errorf("expected no detections outside transcript mode, got %+v", detections)
+	}
+	text = "my social is nine nine nine, forty five, six seven eight nine"
+	if detections, _ := d.Detect(context.Background(), model.DetectionRequest{Text: text, Transcript: true}); len(detections) != 0 {
+		t.Errorf("expected the invalid SSN to be rejected, got %+v", detections)
+	}
+}